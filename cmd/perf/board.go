@@ -0,0 +1,34 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"perf/pkg/config"
+	"perf/pkg/jirautils"
+)
+
+func runBoard(out io.Writer, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("board", flag.ExitOnError)
+	var project string
+	fs.StringVar(&project, "project", "DX", "key of the Jira project")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	workflow := jirautils.DefaultWorkflow
+	if statuses, ok := cfg.BoardWorkflows[project]; ok {
+		workflow = jirautils.NewWorkflow(statuses)
+	}
+
+	router, err := initJira(cfg)
+	if err != nil {
+		return err
+	}
+
+	board, err := jirautils.GetBoard(router.ProjectClient(project), project, workflow)
+	if err != nil {
+		return err
+	}
+	board.Render(out)
+	return nil
+}
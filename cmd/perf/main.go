@@ -1,15 +1,13 @@
 package main
 
 import (
-	"context"
 	"fmt"
 	"io"
 	"log"
 	"log/slog"
 	"os"
-	"perf/pkg/gh"
+	"perf/pkg/config"
 	"perf/pkg/jirautils"
-	"perf/pkg/openai"
 	"strings"
 	"time"
 )
@@ -23,134 +21,119 @@ func initLogger(w io.Writer, minimalLogLevel slog.Level) *slog.Logger {
 }
 
 const (
-	ORG       = "goflink"
-	USERNAME  = "Kristina-Pianykh"
-	JIRA_USER = "Kristina Pianykh"
+	ORG      = "goflink"
+	USERNAME = "Kristina-Pianykh"
+	JIRA_URL = "https://goflink.atlassian.net"
 )
 
+const dateLayout = "2006-01-02"
+
 func main() {
 	out := os.Stdout
 	logger := initLogger(out, slog.LevelDebug)
 	slog.SetDefault(logger)
 
-	from := "2025-06-16"
-	to := "2025-06-17"
+	command, args := "report", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
 
-	jiraClient, err := jirautils.InitJiraClient()
+	cfg, err := loadConfig()
 	if err != nil {
-		fmt.Fprintf(out, "failed to create a Jira client: %s", err.Error())
-		return
+		log.Fatal(err)
 	}
 
-	// project := "Developer Experience"
-	// projectId := jirautils.GetProjectId(jiraClient, project)
-	// fmt.Printf("%s project has ID: %s\n", project, projectId)
-
-	// changelogs, err := jirautils.GetUpdatedTickets(jiraClient, from, to, JIRA_NAME)
-	// if err != nil {
-	// 	log.Fatal(err)
-	// }
-	//
-	// for i, changelog := range changelogs {
-	// 	fmt.Printf("Changelog %d: %s\n\n\n", i, changelog.String())
-	// }
-
-	filter := jirautils.Filter{
-		Name: "Created today",
-		Jql:  fmt.Sprintf("project = DX AND type IN (standardIssueTypes(), subTaskIssueTypes()) AND reporter = \"%s\" AND created >= \"%s\" AND created <= \"%s\" ORDER BY created DESC", JIRA_USER, from, to),
+	switch command {
+	case "report":
+		err = runReport(out, cfg, args)
+	case "stats":
+		err = runStats(out, cfg, args)
+	case "queue":
+		err = runQueue(out, cfg, args)
+	case "worklog":
+		err = runWorklog(out, cfg, args)
+	case "sprint":
+		err = runSprint(out, cfg, args)
+	case "board":
+		err = runBoard(out, cfg, args)
+	case "stale":
+		err = runStale(out, cfg, args)
+	case "standup":
+		err = runStandup(out, cfg, args)
+	default:
+		err = fmt.Errorf("unknown command %q", command)
 	}
-	newTickets, err := jirautils.GetTicketsByFilter(jiraClient, &filter)
 	if err != nil {
 		log.Fatal(err)
 	}
+}
 
-	// updateFilter, err := jirautils.CreateFilter(
-	// 	jiraClient,
-	// 	"Get all updated issues for today",
-	// 	"project = DX AND type IN (standardIssueTypes(), subTaskIssueTypes()) AND assignee = currentUser() AND updated >= \"2025-05-24\" AND updated <= \"2025-05-25\" ORDER BY created DESC",
-	// )
-	// if err != nil {
-	// 	slog.Error("failed to create a filter for updated tickets: %s", err.Error())
-	// }
-	// // fmt.Printf("created an update filter: %v\n", *updateFilter)
-	//
-	// createFilter, err := jirautils.CreateFilter(
-	// 	jiraClient,
-	// 	"created issues for today",
-	// 	"project = DX AND type IN (standardIssueTypes(), subTaskIssueTypes()) AND created >= \"2025-05-24\" AND created <= \"2025-05-25\" AND reporter = currentUser() ORDER BY created DESC",
-	// )
-	// if err != nil {
-	// 	slog.Error("failed to create a filter for created issues: %s", err.Error())
-	// }
-	// // fmt.Printf("\ncreated a create filter: %v\n", *createFilter)
-	//
-	// updatedIssues, err := jirautils.GetIssues(jiraClient, updateFilter)
-	// createdIssues, err := jirautils.GetIssues(jiraClient, createFilter)
-	// fmt.Printf("updated issues: %d\n", len(updatedIssues))
-	// fmt.Printf("created issues: %d\n", len(createdIssues))
-
-	// tickets, err := jirautils.GetBoard(jiraClient)
-	// if err != nil {
-	// 	fmt.Printf("%s", err.Error())
-	// }
-	//
-	// for _, ticket := range tickets {
-	// 	fmt.Printf("Ticket: %s\n\n\n", ticket.String())
-	// }
-
-	ghClient, err := gh.InitClient()
+// loadConfig reads the config file and falls back to the built-in defaults
+// for every setting that is not configured.
+func loadConfig() (*config.Config, error) {
+	path, err := config.Path()
 	if err != nil {
-		fmt.Fprintf(out, "failed to create a GitHub client: %s", err.Error())
-		return
+		return nil, err
 	}
-
-	ctx := context.Background()
-	prs, err := gh.GetPullRequestsByDate(ghClient, ctx, ORG, USERNAME, from, to)
+	cfg, err := config.Load(path)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
 	}
 
-	relevantTickets, err := jirautils.AggPullRequestsByTicket(jiraClient, prs)
-	if err != nil {
-		log.Fatal(err)
+	if cfg.GitHubUser == "" {
+		cfg.GitHubUser = USERNAME
 	}
-
-	var inputBuilder strings.Builder
-	inputBuilder.WriteString(fmt.Sprintf("Date: %s\n", from))
-	inputBuilder.WriteString("Jira Tickets created todday:\n")
-	for _, ticket := range newTickets {
-		inputBuilder.WriteString(fmt.Sprintf("%s\n", ticket.String()))
+	if len(cfg.Orgs) == 0 {
+		cfg.Orgs = []string{ORG}
 	}
-
-	inputBuilder.WriteString("\n\nIndividual contributions by Jira Ticket\n")
-	for key, ticket := range relevantTickets {
-		inputBuilder.WriteString(fmt.Sprintf("TICKET [%s]: %s\n\n", key, ticket))
+	if cfg.ReviewSLA == 0 {
+		cfg.ReviewSLA = config.Duration(24 * time.Hour)
 	}
-
-	reviewsByPR, err := gh.GetReviewedPullRequests(ghClient, ctx, ORG, USERNAME, from, to)
-	if err != nil {
-		log.Fatal(err)
+	if len(cfg.DeployEnvironments) == 0 {
+		cfg.DeployEnvironments = []string{"production"}
 	}
-
-	inputBuilder.WriteString("\n\nReveiwed Pull Requests\n")
-	for _, reviewByPR := range reviewsByPR {
-		inputBuilder.WriteString(reviewByPR.String())
+	if len(cfg.JiraInstances) == 0 {
+		cfg.JiraInstances = []config.JiraInstance{{URL: JIRA_URL}}
 	}
-
-	aiClient, err := openai.InitClient()
-	if err != nil {
-		log.Fatal(err)
+	for i := range cfg.JiraInstances {
+		instance := &cfg.JiraInstances[i]
+		if instance.UsernameEnv == "" {
+			instance.UsernameEnv = "JIRA_USERNAME"
+		}
+		if instance.TokenEnv == "" {
+			instance.TokenEnv = "JIRA_API_TOKEN"
+		}
 	}
-	input := inputBuilder.String()
-	if err = os.WriteFile("./input.txt", []byte(input), 0644); err != nil {
-		log.Fatal(err)
+	if cfg.StoryPointsField == "" {
+		cfg.StoryPointsField = "customfield_10016"
+	}
+	if cfg.SprintField == "" {
+		cfg.SprintField = "customfield_10020"
 	}
+	return cfg, nil
+}
 
-	output, err := openai.Complete(aiClient, context.Background(), &input)
-	if err != nil {
-		log.Fatal(err)
+// initJira creates a client for every configured Jira instance.
+func initJira(cfg *config.Config) (*jirautils.Router, error) {
+	router := jirautils.NewRouter()
+	for _, instance := range cfg.JiraInstances {
+		client, err := jirautils.InitJiraClient(&jirautils.Instance{
+			URL:              instance.URL,
+			DataCenter:       instance.DataCenter,
+			Username:         os.Getenv(instance.UsernameEnv),
+			Token:            os.Getenv(instance.TokenEnv),
+			CustomFields:     cfg.CustomFields,
+			SprintField:      cfg.SprintField,
+			StoryPointsField: cfg.StoryPointsField,
+			EpicLinkField:    instance.EpicLinkField,
+			Location:         time.Local,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create a Jira client: %w", err)
+		}
+		router.Add(client, instance.Projects)
 	}
-	fmt.Println(*output)
+	return router, nil
 }
 
 func today() time.Time {
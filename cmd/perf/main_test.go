@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"perf/pkg/config"
+	"perf/pkg/jirautils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreatedFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		expected string
+	}{
+		{name: "one day", from: "2025-06-16", to: "2025-06-16", expected: `created >= "2025-06-16" AND created < "2025-06-17"`},
+		{name: "month end", from: "2025-06-27", to: "2025-06-30", expected: `created >= "2025-06-27" AND created < "2025-07-01"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := createdFilter(&config.Config{}, "712020:abc", tt.from, tt.to)
+			require.NoError(t, err)
+			assert.Contains(t, filter.Jql, tt.expected)
+			assert.Contains(t, filter.Jql, `reporter = "712020:abc"`)
+			assert.NotContains(t, filter.Jql, "project =")
+		})
+	}
+
+	_, err := createdFilter(&config.Config{}, "712020:abc", "2025-06-16", "tomorrow")
+	assert.Error(t, err)
+}
+
+func TestConfirmAndPost(t *testing.T) {
+	loc := time.FixedZone("CEST", 2*60*60)
+	proposals := []*jirautils.Worklog{
+		{Ticket: "DX-75", Started: time.Date(2025, 6, 16, 9, 0, 0, 0, loc), TimeSpent: 90 * time.Minute},
+		{Ticket: "DX-408", Started: time.Date(2025, 6, 16, 14, 15, 0, 0, loc), TimeSpent: 30 * time.Minute},
+	}
+
+	tests := []struct {
+		name      string
+		proposals []*jirautils.Worklog
+		yes       bool
+		answer    string
+		postErr   error
+		posted    []string
+		output    []string
+		wantErr   bool
+	}{
+		{
+			name:   "nothing to log",
+			output: []string{"Nothing to log."},
+		},
+		{
+			name:      "confirmed",
+			proposals: proposals,
+			answer:    "y\n",
+			posted:    []string{"DX-75", "DX-408"},
+			output:    []string{"- DX-75: 1h 30m from 09:00", "- DX-408: 30m from 14:15", "Post 2 worklogs? [y/N]", "Posted 2 worklogs."},
+		},
+		{
+			name:      "declined",
+			proposals: proposals,
+			answer:    "\n",
+			output:    []string{"Post 2 worklogs? [y/N]", "Aborted."},
+		},
+		{
+			name:      "without asking",
+			proposals: proposals,
+			yes:       true,
+			posted:    []string{"DX-75", "DX-408"},
+			output:    []string{"Posted 2 worklogs."},
+		},
+		{
+			name:      "post fails",
+			proposals: proposals,
+			yes:       true,
+			postErr:   errors.New("forbidden"),
+			posted:    []string{"DX-75"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			var posted []string
+			err := confirmAndPost(&out, strings.NewReader(tt.answer), tt.yes, tt.proposals, func(w *jirautils.Worklog) error {
+				posted = append(posted, w.Ticket)
+				return tt.postErr
+			})
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.posted, posted)
+			for _, line := range tt.output {
+				assert.Contains(t, out.String(), line)
+			}
+			if tt.yes {
+				assert.NotContains(t, out.String(), "[y/N]")
+			}
+		})
+	}
+}
+
+func TestRunReportFlags(t *testing.T) {
+	var out bytes.Buffer
+	assert.ErrorContains(t, runReport(&out, &config.Config{}, []string{"-patch-lines", "-1"}), "-patch-lines")
+	assert.ErrorContains(t, runReport(&out, &config.Config{}, []string{"-guard", "drop"}), "unknown guard")
+}
+
+func TestRunWorklogFlags(t *testing.T) {
+	var out bytes.Buffer
+	assert.ErrorContains(t, runWorklog(&out, &config.Config{}, []string{"-gap", "0s"}), "-gap")
+	assert.ErrorContains(t, runWorklog(&out, &config.Config{}, []string{"-lead", "-5m"}), "-lead")
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"perf/pkg/config"
+	"perf/pkg/gh"
+	"perf/pkg/queue"
+	"time"
+)
+
+func runQueue(out io.Writer, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("queue", flag.ExitOnError)
+	var sla time.Duration
+	fs.DurationVar(&sla, "sla", time.Duration(cfg.ReviewSLA), "maximum age of a pending review request")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ghClient, err := gh.InitClient()
+	if err != nil {
+		return fmt.Errorf("failed to create a GitHub client: %w", err)
+	}
+
+	items, err := queue.Collect(ghClient, context.Background(), cfg.Orgs, cfg.GitHubUser, sla, time.Now())
+	if err != nil {
+		return err
+	}
+	queue.Render(out, items, sla)
+	return nil
+}
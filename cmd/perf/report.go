@@ -0,0 +1,333 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"perf/pkg/config"
+	"perf/pkg/entry"
+	"perf/pkg/gh"
+	"perf/pkg/identity"
+	"perf/pkg/jirautils"
+	"perf/pkg/llm"
+	"perf/pkg/localgit"
+	"perf/pkg/metrics"
+	"perf/pkg/openai"
+	"perf/pkg/report"
+	"slices"
+	"strings"
+	"time"
+)
+
+// createdFilter selects the tickets I created between from and to (both
+// inclusive). Jira reads a bare date as midnight, so the end is the day after to.
+func createdFilter(cfg *config.Config, accountID, from, to string) (*jirautils.Filter, error) {
+	end, err := time.Parse(dateLayout, to)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date string '%s': %w", to, err)
+	}
+	return &jirautils.Filter{
+		ID:   cfg.JiraFilterIDs["created"],
+		Name: "Created today",
+		Jql: fmt.Sprintf("type IN (standardIssueTypes(), subTaskIssueTypes()) AND reporter = \"%s\" AND created >= \"%s\" AND created < \"%s\" ORDER BY created DESC",
+			accountID, from, end.AddDate(0, 0, 1).Format(dateLayout)),
+	}, nil
+}
+
+func runReport(out io.Writer, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	var from, to string
+	var withStats bool
+	var patchLines int
+	var noCache, asJSON, reprompt bool
+	var retries int
+	var guard string
+	fs.StringVar(&from, "from", yesterday().Format(dateLayout), "start date (YYYY-MM-DD)")
+	fs.StringVar(&to, "to", today().Format(dateLayout), "end date (YYYY-MM-DD)")
+	fs.BoolVar(&withStats, "stats", false, "inject PR cycle-time metrics into the report input")
+	fs.BoolVar(&noCache, "no-cache", false, "summarize again instead of reusing cached summaries")
+	fs.BoolVar(&asJSON, "json", false, "print the entry as JSON instead of Markdown")
+	fs.IntVar(&retries, "retries", 2, "times to ask the model again after an answer that does not match the schema")
+	fs.StringVar(&guard, "guard", entry.GuardFlag, "what to do with references to tickets and PRs that were not collected: flag, strip or off")
+	fs.BoolVar(&reprompt, "reprompt", false, "ask the model again (within -retries) if the entry references unknown tickets or PRs")
+	fs.IntVar(&patchLines, "patch-lines", 20, "diff lines per changed file in the report input (0 leaves diffs out)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !slices.Contains([]string{entry.GuardFlag, entry.GuardStrip, entry.GuardOff}, guard) {
+		return fmt.Errorf("unknown guard %q, use flag, strip or off", guard)
+	}
+	if patchLines < 0 {
+		return fmt.Errorf("-patch-lines must be 0 or more, got %d", patchLines)
+	}
+
+	router, err := initJira(cfg)
+	if err != nil {
+		return err
+	}
+	jiraClient := router.Default()
+
+	me, err := jirautils.GetCurrentUser(jiraClient)
+	if err != nil {
+		return err
+	}
+	accountID := jirautils.UserID(me)
+
+	people := identity.NewDirectory(cfg.People)
+	people.Add(config.Person{Name: me.DisplayName, GitHub: cfg.GitHubUser, JiraAccountID: accountID})
+
+	// My changes, comments and new tickets live on every instance, each
+	// knowing me by its own account ID.
+	changelogs := []*jirautils.ChangelogItem{}
+	discussions := []*jirautils.Ticket{}
+	newTickets := []*jirautils.Ticket{}
+	for _, client := range router.Clients() {
+		clientAccountID := accountID
+		if client != jiraClient {
+			if clientAccountID, err = jirautils.GetCurrentAccountID(client); err != nil {
+				return err
+			}
+		}
+
+		clientChangelogs, err := jirautils.GetUpdatedTickets(client, from, to, clientAccountID)
+		if err != nil {
+			return err
+		}
+		changelogs = append(changelogs, clientChangelogs...)
+
+		clientDiscussions, err := jirautils.GetDiscussions(client, from, to, clientAccountID)
+		if err != nil {
+			return err
+		}
+		discussions = append(discussions, clientDiscussions...)
+
+		created, err := createdFilter(cfg, clientAccountID, from, to)
+		if err != nil {
+			return err
+		}
+		if client != jiraClient {
+			// saved filters are looked up on the default instance only
+			created.ID = ""
+		}
+		clientTickets, err := jirautils.GetTicketsByFilter(client, created)
+		if err != nil {
+			return err
+		}
+		for _, ticket := range clientTickets {
+			if err := ticket.FilterComments(from, to, clientAccountID, client.Location()); err != nil {
+				return err
+			}
+			if err := ticket.FetchWorklogs(client, from, to, clientAccountID); err != nil {
+				return err
+			}
+		}
+		newTickets = append(newTickets, clientTickets...)
+	}
+
+	ghClient, err := gh.InitClient()
+	if err != nil {
+		return fmt.Errorf("failed to create a GitHub client: %w", err)
+	}
+
+	ctx := context.Background()
+	prs := []*gh.PullRequest{}
+	for _, org := range cfg.Orgs {
+		orgPRs, err := gh.GetPullRequestsByDate(ghClient, ctx, org, cfg.GitHubUser, from, to)
+		if err != nil {
+			return err
+		}
+		prs = append(prs, orgPRs...)
+	}
+
+	for _, pr := range prs {
+		if err := pr.FetchDelivery(ghClient, ctx, cfg.DeployEnvironments); err != nil {
+			return err
+		}
+		if err := pr.FetchLinks(ghClient, ctx); err != nil {
+			return err
+		}
+	}
+
+	relevantTickets, err := jirautils.AggPullRequestsByTicket(router, prs)
+	if err != nil {
+		return err
+	}
+	for _, ticket := range relevantTickets {
+		ticketAccountID, err := router.CurrentAccountID(ticket.Key)
+		if err != nil {
+			return err
+		}
+		if err := ticket.FilterComments(from, to, ticketAccountID, router.Client(ticket.Key).Location()); err != nil {
+			return err
+		}
+		if err := ticket.FetchWorklogs(router.Client(ticket.Key), from, to, ticketAccountID); err != nil {
+			return err
+		}
+	}
+
+	tickets := slices.SortedFunc(maps.Values(relevantTickets), func(a, b *jirautils.Ticket) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	if err := jirautils.ResolveEpics(router, tickets); err != nil {
+		return err
+	}
+
+	for _, ticket := range slices.Concat(newTickets, tickets, discussions) {
+		people.ApplyToTicket(ticket)
+	}
+
+	input := report.Input{
+		From:        from,
+		To:          to,
+		People:      people.People(),
+		Created:     report.NewTickets(newTickets),
+		Updates:     report.NewUpdates(changelogs),
+		Groups:      report.NewGroups(jirautils.GroupTickets(tickets)),
+		Discussions: report.NewTickets(discussions),
+	}
+
+	if len(cfg.LocalRepos) > 0 {
+		localCommits, err := localgit.Scan(cfg.LocalRepos, cfg.GitAuthor, from, to)
+		if err != nil {
+			return err
+		}
+		input.LocalCommits = report.NewLocalCommits(localgit.GroupByTicket(localCommits))
+	}
+
+	reviewsByPR := map[string]*gh.ReviewsByPullRequest{}
+	for _, org := range cfg.Orgs {
+		orgReviews, err := gh.GetReviewedPullRequests(ghClient, ctx, org, cfg.GitHubUser, from, to)
+		if err != nil {
+			return err
+		}
+		maps.Copy(reviewsByPR, orgReviews)
+	}
+	for _, reviewByPR := range reviewsByPR {
+		people.ApplyToReviews(reviewByPR)
+	}
+	input.Reviews = report.NewReviews(reviewsByPR)
+
+	if withStats {
+		summary, err := metrics.Collect(ghClient, ctx, cfg.Orgs, cfg.GitHubUser, from, to)
+		if err != nil {
+			return err
+		}
+		input.Metrics = summary.String()
+	}
+
+	summarizer, err := llm.New(cfg.LLM)
+	if err != nil {
+		return err
+	}
+	tmpl, err := openai.LoadPrompt(cfg.LLM.PromptPath)
+	if err != nil {
+		return err
+	}
+	promptData := openai.PromptData{
+		Date:         from,
+		User:         me.DisplayName,
+		PullRequests: prs,
+	}
+	seen := map[string]bool{}
+	for _, ticket := range slices.Concat(newTickets, tickets, discussions) {
+		if !seen[ticket.Key] {
+			seen[ticket.Key] = true
+			promptData.Tickets = append(promptData.Tickets, ticket)
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(reviewsByPR)) {
+		promptData.Reviews = append(promptData.Reviews, reviewsByPR[key])
+	}
+	prompt, err := openai.RenderPrompt(tmpl, &promptData)
+	if err != nil {
+		return err
+	}
+	prompt += "\n\n" + entry.Instructions()
+
+	renderer := report.Renderer{PatchLines: patchLines}
+	var rendered strings.Builder
+	renderer.Render(&rendered, &input)
+	if err = os.WriteFile("./input.txt", []byte(rendered.String()), 0644); err != nil {
+		return err
+	}
+
+	reviewedPRs := []*gh.PullRequest{}
+	for _, review := range promptData.Reviews {
+		reviewedPRs = append(reviewedPRs, review.PullRequest)
+	}
+	known := entry.NewKnown(promptData.Tickets, slices.Concat(prs, reviewedPRs))
+	for _, changelog := range changelogs {
+		known.AddKeys(changelog.Ticket.Key)
+	}
+	for _, tc := range input.LocalCommits {
+		known.AddKeys(tc.Ticket)
+	}
+
+	mapReduce := llm.MapReduce{
+		Summarizer:       summarizer,
+		Model:            llm.ModelName(cfg.LLM),
+		MaxInputTokens:   cfg.LLM.MaxInputTokens,
+		MaxSummaryTokens: cfg.LLM.MaxSummaryTokens,
+		Schema:           entry.Schema,
+		Validate: func(output string) error {
+			logEntry, err := entry.Parse(output)
+			if err != nil || !reprompt || guard == entry.GuardOff {
+				return err
+			}
+			return known.Verify(logEntry)
+		},
+		Retries: retries,
+	}
+	if !noCache {
+		cacheDir := cfg.LLM.CacheDir
+		if cacheDir == "" {
+			if cacheDir, err = llm.DefaultCacheDir(); err != nil {
+				return err
+			}
+		}
+		mapReduce.Cache = &llm.Cache{Dir: cacheDir}
+	}
+
+	doc := llm.Document{Text: rendered.String()}
+	doc.Header, doc.Parts = renderer.Split(&input)
+	output, err := mapReduce.Summarize(ctx, prompt, &doc)
+	var unknown *entry.UnknownReferencesError
+	if errors.As(err, &unknown) {
+		// the guard below takes care of the references the model insists on
+		slog.Warn("entry still references unknown tickets or pull requests", slog.String("error", unknown.Error()))
+	} else if err != nil {
+		return err
+	}
+	logEntry, err := entry.Parse(output)
+	if err != nil {
+		return err
+	}
+
+	switch guard {
+	case entry.GuardFlag:
+		known.Flag(logEntry)
+	case entry.GuardStrip:
+		known.Strip(logEntry)
+	}
+
+	if asJSON {
+		data, err := logEntry.JSON()
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(out, data)
+		return nil
+	}
+
+	ticketsByKey := map[string]*jirautils.Ticket{}
+	for _, ticket := range promptData.Tickets {
+		ticketsByKey[ticket.Key] = ticket
+	}
+	logEntry.Render(out, promptData.Day(), ticketsByKey)
+	return nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"perf/pkg/config"
+	"perf/pkg/jirautils"
+)
+
+func runSprint(out io.Writer, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("sprint", flag.ExitOnError)
+	var sprintID int
+	fs.IntVar(&sprintID, "sprint", 0, "ID of the sprint to summarize (default: the active sprint of jira_board_id)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	router, err := initJira(cfg)
+	if err != nil {
+		return err
+	}
+	jiraClient := router.Default()
+
+	var sprint *jirautils.Sprint
+	if sprintID != 0 {
+		sprint, err = jirautils.GetSprint(jiraClient, sprintID)
+	} else {
+		if cfg.JiraBoardID == 0 {
+			return fmt.Errorf("no sprint given and jira_board_id is not configured")
+		}
+		sprint, err = jirautils.GetActiveSprint(jiraClient, cfg.JiraBoardID)
+	}
+	if err != nil {
+		return err
+	}
+	if sprint == nil {
+		return fmt.Errorf("board %d has no active sprint", cfg.JiraBoardID)
+	}
+
+	tickets, err := jirautils.GetSprintTickets(jiraClient, sprint.ID, "assignee = currentUser()", cfg.StoryPointsField)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(out, jirautils.SummarizeSprint(sprint, tickets))
+	return nil
+}
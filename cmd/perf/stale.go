@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"perf/pkg/config"
+	"perf/pkg/gh"
+	"perf/pkg/jirautils"
+	"perf/pkg/localgit"
+	"perf/pkg/stale"
+	"time"
+)
+
+func runStale(out io.Writer, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("stale", flag.ExitOnError)
+	var days int
+	fs.IntVar(&days, "days", 5, "days without activity after which work counts as stale")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	since := now.AddDate(0, 0, -days).Format(dateLayout)
+
+	router, err := initJira(cfg)
+	if err != nil {
+		return err
+	}
+	tickets := []*jirautils.Ticket{}
+	for _, client := range router.Clients() {
+		active, err := stale.GetActiveTickets(client)
+		if err != nil {
+			return err
+		}
+		tickets = append(tickets, active...)
+	}
+
+	ghClient, err := gh.InitClient()
+	if err != nil {
+		return fmt.Errorf("failed to create a GitHub client: %w", err)
+	}
+	prs, err := stale.GetPullRequests(ghClient, context.Background(), cfg.Orgs, cfg.GitHubUser, since)
+	if err != nil {
+		return err
+	}
+
+	commits := []*gh.Commit{}
+	if len(cfg.LocalRepos) > 0 {
+		commits, err = localgit.Scan(cfg.LocalRepos, cfg.GitAuthor, since, now.Format(dateLayout))
+		if err != nil {
+			return err
+		}
+	}
+
+	threshold := time.Duration(days) * 24 * time.Hour
+	stale.Render(out, stale.Detect(tickets, prs, commits, threshold, now))
+	return nil
+}
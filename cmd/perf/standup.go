@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"perf/pkg/config"
+	"perf/pkg/gh"
+	"perf/pkg/jirautils"
+	"perf/pkg/standup"
+	"time"
+)
+
+func runStandup(out io.Writer, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("standup", flag.ExitOnError)
+	var since, project string
+	fs.StringVar(&since, "since", previousWorkday(today()).Format(dateLayout), "first day of the activity to report (YYYY-MM-DD)")
+	fs.StringVar(&project, "project", "DX", "key of the Jira project whose board plans today")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	until := yesterday().Format(dateLayout)
+	if until < since {
+		until = since
+	}
+
+	router, err := initJira(cfg)
+	if err != nil {
+		return err
+	}
+	activity := standup.Activity{Reviews: map[string]*gh.ReviewsByPullRequest{}}
+	jiraClient := router.Default()
+	for _, client := range router.Clients() {
+		accountID, err := jirautils.GetCurrentAccountID(client)
+		if err != nil {
+			return err
+		}
+
+		changelogs, err := jirautils.GetUpdatedTickets(client, since, until, accountID)
+		if err != nil {
+			return err
+		}
+		activity.Changelogs = append(activity.Changelogs, changelogs...)
+
+		created, err := createdFilter(cfg, accountID, since, until)
+		if err != nil {
+			return err
+		}
+		if client != jiraClient {
+			// saved filters are looked up on the default instance only
+			created.ID = ""
+		}
+		tickets, err := jirautils.GetTicketsByFilter(client, created)
+		if err != nil {
+			return err
+		}
+		activity.Created = append(activity.Created, tickets...)
+	}
+
+	workflow := jirautils.DefaultWorkflow
+	if statuses, ok := cfg.BoardWorkflows[project]; ok {
+		workflow = jirautils.NewWorkflow(statuses)
+	}
+	board, err := jirautils.GetBoard(router.ProjectClient(project), project, workflow)
+	if err != nil {
+		return err
+	}
+
+	ghClient, err := gh.InitClient()
+	if err != nil {
+		return fmt.Errorf("failed to create a GitHub client: %w", err)
+	}
+	ctx := context.Background()
+	for _, org := range cfg.Orgs {
+		prs, err := gh.GetPullRequestsByDate(ghClient, ctx, org, cfg.GitHubUser, since, until)
+		if err != nil {
+			return err
+		}
+		activity.PullRequests = append(activity.PullRequests, prs...)
+
+		reviews, err := gh.GetReviewedPullRequests(ghClient, ctx, org, cfg.GitHubUser, since, until)
+		if err != nil {
+			return err
+		}
+		maps.Copy(activity.Reviews, reviews)
+	}
+
+	awaitingReview, err := standup.GetAwaitingReview(ghClient, ctx, cfg.Orgs, cfg.GitHubUser)
+	if err != nil {
+		return err
+	}
+
+	standup.Build(&activity, board, awaitingReview).Render(out)
+	return nil
+}
+
+// previousWorkday returns the last weekday before day, so that the standup on
+// Monday covers Friday.
+func previousWorkday(day time.Time) time.Time {
+	previous := day.AddDate(0, 0, -1)
+	for previous.Weekday() == time.Saturday || previous.Weekday() == time.Sunday {
+		previous = previous.AddDate(0, 0, -1)
+	}
+	return previous
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"perf/pkg/config"
+	"perf/pkg/gh"
+	"perf/pkg/metrics"
+)
+
+func runStats(out io.Writer, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	var from, to string
+	fs.StringVar(&from, "from", yesterday().Format(dateLayout), "start date (YYYY-MM-DD)")
+	fs.StringVar(&to, "to", today().Format(dateLayout), "end date (YYYY-MM-DD)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ghClient, err := gh.InitClient()
+	if err != nil {
+		return fmt.Errorf("failed to create a GitHub client: %w", err)
+	}
+
+	summary, err := metrics.Collect(ghClient, context.Background(), cfg.Orgs, cfg.GitHubUser, from, to)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(out, summary.String())
+	return nil
+}
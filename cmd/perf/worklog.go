@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"perf/pkg/config"
+	"perf/pkg/gh"
+	"perf/pkg/jirautils"
+	"perf/pkg/localgit"
+	"strings"
+	"time"
+)
+
+func runWorklog(out io.Writer, cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("worklog", flag.ExitOnError)
+	var date string
+	var gap, lead time.Duration
+	var yes bool
+	fs.StringVar(&date, "date", today().Format(dateLayout), "day to propose worklogs for (YYYY-MM-DD)")
+	fs.DurationVar(&gap, "gap", 2*time.Hour, "maximum pause between commits of one work session")
+	fs.DurationVar(&lead, "lead", 30*time.Minute, "time worked before the first commit of a session")
+	fs.BoolVar(&yes, "yes", false, "post the proposed worklogs without asking")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if gap <= 0 {
+		return fmt.Errorf("-gap must be positive, got %s", gap)
+	}
+	if lead < 0 {
+		return fmt.Errorf("-lead must not be negative, got %s", lead)
+	}
+
+	router, err := initJira(cfg)
+	if err != nil {
+		return err
+	}
+
+	commits, err := collectCommits(cfg, date)
+	if err != nil {
+		return err
+	}
+
+	loc := router.Default().Location()
+	proposals, err := dropLoggedTickets(router, jirautils.ProposeWorklogs(commits, gap, lead, loc), date)
+	if err != nil {
+		return err
+	}
+	return confirmAndPost(out, os.Stdin, yes, proposals, func(w *jirautils.Worklog) error {
+		return jirautils.PostWorklog(router.Client(w.Ticket), w)
+	})
+}
+
+// collectCommits gathers the commits of the day from my pull requests and
+// from the configured local clones, each attributed to a ticket.
+func collectCommits(cfg *config.Config, date string) ([]*gh.Commit, error) {
+	commits := []*gh.Commit{}
+
+	ghClient, err := gh.InitClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a GitHub client: %w", err)
+	}
+	for _, org := range cfg.Orgs {
+		prs, err := gh.GetPullRequestsByDate(ghClient, context.Background(), org, cfg.GitHubUser, date, date)
+		if err != nil {
+			return nil, err
+		}
+		for _, pr := range prs {
+			for _, commit := range pr.Commits {
+				commit.Ticket = pr.Ticket
+				commits = append(commits, commit)
+			}
+		}
+	}
+
+	if len(cfg.LocalRepos) > 0 {
+		localCommits, err := localgit.Scan(cfg.LocalRepos, cfg.GitAuthor, date, date)
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, localCommits...)
+	}
+	return commits, nil
+}
+
+// dropLoggedTickets removes the proposals for tickets I already logged work on that day.
+func dropLoggedTickets(router *jirautils.Router, proposals []*jirautils.Worklog, date string) ([]*jirautils.Worklog, error) {
+	logged := map[string]bool{}
+	remaining := []*jirautils.Worklog{}
+	for _, proposal := range proposals {
+		if _, checked := logged[proposal.Ticket]; !checked {
+			accountID, err := router.CurrentAccountID(proposal.Ticket)
+			if err != nil {
+				return nil, err
+			}
+			ticket := jirautils.Ticket{Key: proposal.Ticket}
+			if err := ticket.FetchWorklogs(router.Client(proposal.Ticket), date, date, accountID); err != nil {
+				return nil, err
+			}
+			logged[proposal.Ticket] = len(ticket.Worklogs) > 0
+		}
+		if !logged[proposal.Ticket] {
+			remaining = append(remaining, proposal)
+		}
+	}
+	return remaining, nil
+}
+
+func confirmAndPost(out io.Writer, in io.Reader, yes bool, proposals []*jirautils.Worklog, post func(*jirautils.Worklog) error) error {
+	if len(proposals) == 0 {
+		fmt.Fprintln(out, "Nothing to log.")
+		return nil
+	}
+
+	fmt.Fprintln(out, "Proposed worklogs:")
+	for _, proposal := range proposals {
+		fmt.Fprintf(out, "- %s\n", proposal)
+	}
+
+	if !yes {
+		fmt.Fprintf(out, "Post %d worklogs? [y/N] ", len(proposals))
+		answer, _ := bufio.NewReader(in).ReadString('\n')
+		if strings.ToLower(strings.TrimSpace(answer)) != "y" {
+			fmt.Fprintln(out, "Aborted.")
+			return nil
+		}
+	}
+
+	for _, proposal := range proposals {
+		if err := post(proposal); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(out, "Posted %d worklogs.\n", len(proposals))
+	return nil
+}
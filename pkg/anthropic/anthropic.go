@@ -0,0 +1,108 @@
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	DefaultBaseURL = "https://api.anthropic.com"
+	DefaultModel   = "claude-3-5-haiku-latest"
+	apiVersion     = "2023-06-01"
+	maxTokens      = 4096
+)
+
+// Summarizer completes messages with a model of the Anthropic Messages API.
+type Summarizer struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+	model   string
+}
+
+func NewSummarizer(apiKey, baseURL, model string) *Summarizer {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if model == "" {
+		model = DefaultModel
+	}
+	return &Summarizer{client: http.DefaultClient, baseURL: strings.TrimSuffix(baseURL, "/"), apiKey: apiKey, model: model}
+}
+
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type request struct {
+	Model     string    `json:"model"`
+	MaxTokens int       `json:"max_tokens"`
+	System    string    `json:"system,omitempty"`
+	Messages  []message `json:"messages"`
+}
+
+type response struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (s *Summarizer) Summarize(ctx context.Context, system, input string) (string, error) {
+	body, err := json.Marshal(request{
+		Model:     s.model,
+		MaxTokens: maxTokens,
+		System:    system,
+		Messages:  []message{{Role: "user", Content: input}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode the request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/v1/messages", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to prepare a request to %s: %w", s.baseURL, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", s.apiKey)
+	req.Header.Set("anthropic-version", apiVersion)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send a message to %s: %w", s.model, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the response of %s: %w", s.model, err)
+	}
+	result := response{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return "", fmt.Errorf("[%d] failed to decode the response of %s: %w", resp.StatusCode, s.model, err)
+	}
+	if result.Error != nil {
+		return "", fmt.Errorf("[%d] %s: %s", resp.StatusCode, result.Error.Type, result.Error.Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("[%d] message to %s failed", resp.StatusCode, s.model)
+	}
+
+	var text strings.Builder
+	for _, block := range result.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	return text.String(), nil
+}
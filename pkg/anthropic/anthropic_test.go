@@ -0,0 +1,45 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/messages", r.URL.Path)
+		assert.Equal(t, "key", r.Header.Get("x-api-key"))
+		assert.Equal(t, apiVersion, r.Header.Get("anthropic-version"))
+
+		req := request{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, DefaultModel, req.Model)
+		assert.Equal(t, "be brief", req.System)
+		assert.Equal(t, []message{{Role: "user", Content: "activity"}}, req.Messages)
+
+		fmt.Fprint(w, `{"content": [{"type": "text", "text": "- Did "}, {"type": "text", "text": "things"}]}`)
+	}))
+	defer server.Close()
+
+	output, err := NewSummarizer("key", server.URL+"/", "").Summarize(context.Background(), "be brief", "activity")
+	require.NoError(t, err)
+	assert.Equal(t, "- Did things", output)
+}
+
+func TestSummarizeError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"type": "error", "error": {"type": "authentication_error", "message": "invalid x-api-key"}}`)
+	}))
+	defer server.Close()
+
+	_, err := NewSummarizer("wrong", server.URL, "").Summarize(context.Background(), "", "activity")
+	assert.EqualError(t, err, "[401] authentication_error: invalid x-api-key")
+}
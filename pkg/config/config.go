@@ -0,0 +1,161 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+// removedKeys are keys of earlier versions that are no longer read, with
+// what replaced them.
+var removedKeys = map[string]string{
+	"jira_user": "Jira users are identified by account ID now, you via the API token and others via people",
+}
+
+type Config struct {
+	GitHubUser string   `json:"github_user"`
+	Orgs       []string `json:"orgs"`
+	// ReviewSLA is the maximum age of a pending review request, e.g. "24h"
+	ReviewSLA Duration `json:"review_sla"`
+	// DeployEnvironments are the GitHub environments that count as "deployed"
+	DeployEnvironments []string `json:"deploy_environments"`
+	// LocalRepos are clones, or directories of clones, scanned for local commits
+	LocalRepos []string `json:"local_repos"`
+	// GitAuthor matches the author of local commits; defaults to user.email of each clone
+	GitAuthor string `json:"git_author"`
+	// JiraFilterIDs opts into saved Jira filters instead of the built-in JQL, keyed by "created"
+	JiraFilterIDs map[string]string `json:"jira_filter_ids"`
+	// JiraBoardID is the Jira Software board whose active sprint `perf sprint` summarizes
+	JiraBoardID int `json:"jira_board_id"`
+	// StoryPointsField is the ID of the custom field holding story points
+	StoryPointsField string `json:"story_points_field"`
+	// SprintField is the ID of the custom field holding the sprints of a ticket
+	SprintField string `json:"sprint_field"`
+	// BoardWorkflows maps the status names of a project to board columns, keyed by project key
+	BoardWorkflows map[string]map[string]string `json:"board_workflows"`
+	// People links the GitHub logins and Jira accounts of colleagues
+	People []Person `json:"people"`
+	// CustomFields adds custom fields to tickets, mapping a name to the field ID
+	CustomFields map[string]string `json:"custom_fields"`
+	// LLM selects the model that writes the report
+	LLM LLM `json:"llm"`
+	// JiraInstances are the Jira sites to query; tickets are routed by project key
+	JiraInstances []JiraInstance `json:"jira_instances"`
+}
+
+// JiraInstance is a Jira site. Credentials are read from the environment
+// variables named by UsernameEnv and TokenEnv.
+type JiraInstance struct {
+	URL string `json:"url"`
+	// DataCenter selects bearer auth with a personal access token instead of basic auth
+	DataCenter  bool   `json:"data_center"`
+	UsernameEnv string `json:"username_env"`
+	TokenEnv    string `json:"token_env"`
+	// Projects are the project keys hosted by the site; empty means all others
+	Projects []string `json:"projects"`
+	// EpicLinkField is the ID of the "Epic Link" field, which links stories
+	// to their epic on Data Center sites instead of the parent
+	EpicLinkField string `json:"epic_link_field"`
+}
+
+// LLM is the model provider: "openai" (default), "openai-compatible" for a
+// local server such as Ollama, or "anthropic".
+type LLM struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+	BaseURL  string `json:"base_url"`
+	// APIKeyEnv names the environment variable holding the API key
+	APIKeyEnv string `json:"api_key_env"`
+	// PromptPath overrides the built-in prompt with a text/template file
+	PromptPath string `json:"prompt_path"`
+	// MaxInputTokens is the budget of one request; larger input is summarized
+	// per ticket and pull request first
+	MaxInputTokens int `json:"max_input_tokens"`
+	// MaxSummaryTokens is the length asked of every partial summary
+	MaxSummaryTokens int `json:"max_summary_tokens"`
+	// CacheDir keeps the output of every summarization stage
+	CacheDir string `json:"cache_dir"`
+}
+
+// Person is one identity across GitHub and Jira.
+type Person struct {
+	Name          string `json:"name"`
+	GitHub        string `json:"github"`
+	JiraAccountID string `json:"jira_account_id"`
+}
+
+// Duration is a time.Duration that is read from its string form ("36h", "90m").
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string: %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration '%s': %w", s, err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Path returns the location of the config file: $PERF_CONFIG if set,
+// otherwise perf/config.json in the user config directory.
+func Path() (string, error) {
+	if path, ok := os.LookupEnv("PERF_CONFIG"); ok {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the user config directory: %w", err)
+	}
+	return filepath.Join(dir, "perf", "config.json"), nil
+}
+
+// Load reads the config file at path. A missing file yields an empty config.
+func Load(path string) (*Config, error) {
+	cfg := Config{}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	for _, key := range RemovedKeys(data) {
+		slog.Warn("ignoring removed config key", slog.String("path", path), slog.String("key", key), slog.String("hint", removedKeys[key]))
+	}
+	return &cfg, nil
+}
+
+// RemovedKeys returns the keys of the config that are no longer read.
+func RemovedKeys(data []byte) []string {
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil
+	}
+	removed := []string{}
+	for _, key := range slices.Sorted(maps.Keys(keys)) {
+		if _, ok := removedKeys[key]; ok {
+			removed = append(removed, key)
+		}
+	}
+	return removed
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	contents := `{"github_user": "octocat", "orgs": ["goflink", "other"], "review_sla": "36h"}`
+	assert.NoError(t, os.WriteFile(path, []byte(contents), 0644))
+
+	cfg, err := Load(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "octocat", cfg.GitHubUser)
+	assert.Equal(t, []string{"goflink", "other"}, cfg.Orgs)
+	assert.Equal(t, 36*time.Hour, time.Duration(cfg.ReviewSLA))
+}
+
+func TestRemovedKeys(t *testing.T) {
+	assert.Equal(t, []string{"jira_user"}, RemovedKeys([]byte(`{"github_user": "octocat", "jira_user": "Kristina Pianykh"}`)))
+	assert.Empty(t, RemovedKeys([]byte(`{"github_user": "octocat"}`)))
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"jira_user": "Kristina Pianykh"}`), 0644))
+	_, err := Load(path)
+	assert.NoError(t, err)
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "nonexistent.json"))
+	assert.NoError(t, err)
+	assert.NotNil(t, cfg)
+}
+
+func TestLoadInvalidDuration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"review_sla": "a day"}`), 0644))
+
+	_, err := Load(path)
+	assert.Error(t, err)
+}
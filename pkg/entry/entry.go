@@ -0,0 +1,154 @@
+package entry
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"perf/pkg/jirautils"
+	"regexp"
+	"strings"
+)
+
+// Schema is the JSON schema the model answers with. Answers are validated
+// against it.
+//
+//go:embed schema.json
+var Schema string
+
+var schema = mustParseSchema(Schema)
+
+// Categories are the kinds of work a bullet can describe, as listed in the
+// schema.
+var Categories = schema.Properties["bullets"].Items.Properties["category"].Enum
+
+var (
+	pullRequestNumberPattern = regexp.MustCompile(`/pull/([0-9]+)$`)
+	fencePattern             = regexp.MustCompile("(?s)^```[a-z]*\\s*(.*?)\\s*```$")
+)
+
+func mustParseSchema(data string) *schemaNode {
+	node, err := parseSchema(data)
+	if err != nil {
+		panic(err)
+	}
+	return node
+}
+
+// Entry is a daily log entry as returned by the model.
+type Entry struct {
+	Bullets []*Bullet `json:"bullets"`
+}
+
+// Bullet is one piece of work. The ticket link and the pull request
+// references are added to the text when the entry is rendered.
+type Bullet struct {
+	Category     string   `json:"category"`
+	Ticket       string   `json:"ticket,omitempty"`
+	PullRequests []string `json:"pull_requests,omitempty"`
+	Text         string   `json:"text"`
+	// Unverified are the references to tickets and pull requests that were
+	// not collected, set by the guard. It is not part of the schema, so
+	// answers of the model cannot set it.
+	Unverified []string `json:"unverified,omitempty"`
+}
+
+// Instructions tell the model to answer with an entry of the schema. They are
+// appended to the system prompt, also to a configured one.
+func Instructions() string {
+	return "Answer with a single JSON object matching the following JSON schema, without Markdown fences or any other text. " +
+		"The text of a bullet is one or two sentences without the ticket link and without pull request references; " +
+		"name the ticket by its key in \"ticket\" and the pull requests by their URLs in \"pull_requests\".\n" + Schema
+}
+
+// Parse decodes the answer of the model and validates it against the schema.
+// Markdown fences around the JSON are tolerated since local models tend to
+// add them anyway.
+func Parse(output string) (*Entry, error) {
+	text := strings.TrimSpace(output)
+	if match := fencePattern.FindStringSubmatch(text); match != nil {
+		text = match[1]
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(text))
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("answer is not a JSON object of the schema: %w", err)
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("answer has text after the JSON object")
+	}
+	if err := errors.Join(schema.validate("", value)...); err != nil {
+		return nil, err
+	}
+
+	var entry Entry
+	if err := json.Unmarshal([]byte(text), &entry); err != nil {
+		return nil, fmt.Errorf("answer is not a JSON object of the schema: %w", err)
+	}
+	return &entry, nil
+}
+
+// Render writes the entry as Markdown: the day, then one bullet per piece of
+// work with the ticket as link and the pull requests as "See PR" suffix.
+func (e *Entry) Render(w io.Writer, day string, tickets map[string]*jirautils.Ticket) {
+	fmt.Fprintln(w, day)
+	for _, b := range e.Bullets {
+		var line strings.Builder
+		line.WriteString("- ")
+		if b.Ticket != "" {
+			if ticket, ok := tickets[b.Ticket]; ok && ticket.URL != "" {
+				fmt.Fprintf(&line, "[%s (%s)](%s): ", ticket.Title, ticket.Key, ticket.URL)
+			} else {
+				fmt.Fprintf(&line, "%s: ", b.Ticket)
+			}
+		}
+		line.WriteString(sentence(b.Text))
+		if len(b.PullRequests) > 0 {
+			fmt.Fprintf(&line, " %s.", seePullRequests(b.PullRequests))
+		}
+		if len(b.Unverified) > 0 {
+			fmt.Fprintf(&line, " (unverified: %s)", strings.Join(b.Unverified, ", "))
+		}
+		fmt.Fprintln(w, line.String())
+	}
+}
+
+// JSON returns the entry as indented JSON.
+func (e *Entry) JSON() (string, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(e); err != nil {
+		return "", fmt.Errorf("failed to encode entry: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// seePullRequests references the pull requests as "See PR [#31](...)" or
+// "See PRs [#31](...) and [#32](...)".
+func seePullRequests(urls []string) string {
+	links := []string{}
+	for _, url := range urls {
+		number := url
+		if match := pullRequestNumberPattern.FindStringSubmatch(url); match != nil {
+			number = match[1]
+		}
+		links = append(links, fmt.Sprintf("[#%s](%s)", number, url))
+	}
+	if len(links) == 1 {
+		return "See PR " + links[0]
+	}
+	return fmt.Sprintf("See PRs %s and %s", strings.Join(links[:len(links)-1], ", "), links[len(links)-1])
+}
+
+func sentence(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasSuffix(text, ".") && !strings.HasSuffix(text, "!") && !strings.HasSuffix(text, "?") {
+		text += "."
+	}
+	return text
+}
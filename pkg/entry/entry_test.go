@@ -0,0 +1,196 @@
+package entry
+
+import (
+	"bytes"
+	"encoding/json"
+	"perf/pkg/gh"
+	"perf/pkg/jirautils"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		bullets int
+		wantErr string
+	}{
+		{
+			name:    "valid",
+			output:  `{"bullets": [{"category": "feature", "ticket": "DX-408", "pull_requests": ["https://github.com/goflink/krisss/pull/31"], "text": "Added a Nix flake"}]}`,
+			bullets: 1,
+		},
+		{
+			name:    "fenced",
+			output:  "```json\n{\"bullets\": [{\"category\": \"review\", \"text\": \"Reviewed templates\"}]}\n```",
+			bullets: 1,
+		},
+		{name: "empty day", output: `{"bullets": []}`},
+		{name: "markdown", output: "- Implemented things", wantErr: "not a JSON object"},
+		{name: "unknown field", output: `{"bullets": [], "summary": "busy"}`, wantErr: "unknown field"},
+		{name: "trailing text", output: `{"bullets": []} Hope this helps!`, wantErr: "text after the JSON object"},
+		{name: "missing bullets", output: `{}`, wantErr: "bullets is missing"},
+		{name: "category", output: `{"bullets": [{"category": "coding", "text": "Coded"}]}`, wantErr: `bullets[0].category "coding"`},
+		{name: "ticket", output: `{"bullets": [{"category": "fix", "ticket": "dx 408", "text": "Fixed"}]}`, wantErr: `bullets[0].ticket "dx 408"`},
+		{name: "pull request", output: `{"bullets": [{"category": "fix", "pull_requests": ["#31"], "text": "Fixed"}]}`, wantErr: `bullets[0].pull_requests[0] "#31"`},
+		{name: "text", output: `{"bullets": [{"category": "fix", "text": " "}]}`, wantErr: `bullets[0].text " " does not match the pattern`},
+		{name: "missing text", output: `{"bullets": [{"category": "fix"}]}`, wantErr: "bullets[0].text is missing"},
+		{name: "null bullet", output: `{"bullets": [null]}`, wantErr: "bullets[0] is not an object"},
+		{name: "unverified", output: `{"bullets": [{"category": "fix", "text": "Fixed", "unverified": []}]}`, wantErr: `bullets[0] has unknown field "unverified"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, err := Parse(tt.output)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, entry.Bullets, tt.bullets)
+		})
+	}
+}
+
+func TestSchema(t *testing.T) {
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal([]byte(Schema), &schema))
+	assert.Contains(t, Instructions(), Schema)
+
+	properties := schema["properties"].(map[string]any)["bullets"].(map[string]any)["items"].(map[string]any)["properties"].(map[string]any)
+	categories := []string{}
+	for _, c := range properties["category"].(map[string]any)["enum"].([]any) {
+		categories = append(categories, c.(string))
+	}
+	assert.Equal(t, Categories, categories)
+	assert.Equal(t, []string{"feature", "fix", "incident", "review", "discussion", "maintenance", "planning"}, Categories)
+}
+
+func TestRender(t *testing.T) {
+	entry := &Entry{Bullets: []*Bullet{
+		{Category: "feature", Ticket: "DX-408", PullRequests: []string{"https://github.com/goflink/krisss/pull/31"}, Text: "Added a Nix flake"},
+		{Category: "review", PullRequests: []string{
+			"https://github.com/goflink/platform-repo-templates/pull/748",
+			"https://github.com/goflink/platform-repo-templates/pull/749",
+			"https://github.com/goflink/platform-repo-templates/pull/750",
+		}, Text: "Reviewed values.yaml validation of Frank Ittermann."},
+		{Category: "discussion", Ticket: "OPS-4", Text: "Discussed the registry access"},
+	}}
+	tickets := map[string]*jirautils.Ticket{
+		"DX-408": {Key: "DX-408", Title: "Spike: Setup easy and composable Developer Environments", URL: "https://goflink.atlassian.net/browse/DX-408"},
+	}
+
+	var out bytes.Buffer
+	entry.Render(&out, "06.Jun.2025", tickets)
+	assert.Equal(t, `06.Jun.2025
+- [Spike: Setup easy and composable Developer Environments (DX-408)](https://goflink.atlassian.net/browse/DX-408): Added a Nix flake. See PR [#31](https://github.com/goflink/krisss/pull/31).
+- Reviewed values.yaml validation of Frank Ittermann. See PRs [#748](https://github.com/goflink/platform-repo-templates/pull/748), [#749](https://github.com/goflink/platform-repo-templates/pull/749) and [#750](https://github.com/goflink/platform-repo-templates/pull/750).
+- OPS-4: Discussed the registry access.
+`, out.String())
+
+	data, err := entry.JSON()
+	require.NoError(t, err)
+	parsed, err := Parse(data)
+	require.NoError(t, err)
+	assert.Equal(t, entry, parsed)
+}
+
+func testKnown() *Known {
+	tickets := []*jirautils.Ticket{{
+		Key: "DX-408", URL: "https://goflink.atlassian.net/browse/DX-408",
+		Links: []*jirautils.TicketLink{{Relation: "blocks", Key: "DX-411"}},
+		Epic:  &jirautils.TicketRef{Key: "DX-75"},
+		PullRequests: []*gh.PullRequest{{Owner: "goflink", Repo: "krisss", Number: 31, HTMLURL: "https://github.com/goflink/krisss/pull/31",
+			LinkedIssues: []*gh.IssueRef{{Owner: "goflink", Repo: "krisss", Number: 30, URL: "https://github.com/goflink/krisss/issues/30"}}}},
+	}}
+	prs := []*gh.PullRequest{{Owner: "goflink", Repo: "platform-repo-templates", Number: 748, HTMLURL: "https://github.com/goflink/platform-repo-templates/pull/748"}}
+	known := NewKnown(tickets, prs)
+	known.AddKeys("OPS-4")
+	return known
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name    string
+		bullet  Bullet
+		unknown []string
+	}{
+		{
+			name: "known",
+			bullet: Bullet{Ticket: "DX-408", PullRequests: []string{"https://github.com/goflink/krisss/pull/31"},
+				Text: "Added a flake closing #30 for DX-411 under DX-75 and OPS-4, see https://github.com/goflink/krisss/pull/31/files."},
+		},
+		{name: "browse URL of a known key", bullet: Bullet{Text: "Unblocked https://goflink.atlassian.net/browse/DX-411"}},
+		{name: "terms that look like keys", bullet: Bullet{Text: "Switched to UTF-8 and SHA-256"}},
+		{name: "qualified number", bullet: Bullet{Text: "Follow-up to goflink/krisss#31"}},
+		{
+			name:    "unknown project",
+			bullet:  Bullet{Text: "Rolled out OPS-812 and INFRA-7"},
+			unknown: []string{"OPS-812", "INFRA-7"},
+		},
+		{
+			name:    "number of another repository",
+			bullet:  Bullet{PullRequests: []string{"https://github.com/goflink/platform-repo-templates/pull/748"}, Text: "Reviewed #748 after #31 and goflink/dunebot#748"},
+			unknown: []string{"goflink/dunebot#748", "#31"},
+		},
+		{
+			name:    "number without pull requests",
+			bullet:  Bullet{Ticket: "DX-408", Text: "Merged #31"},
+			unknown: []string{"#31"},
+		},
+		{
+			name:    "unknown ticket",
+			bullet:  Bullet{Ticket: "DX-999", Text: "Planned DX-999 and DX-998"},
+			unknown: []string{"DX-999", "DX-998"},
+		},
+		{
+			name:    "unknown pull requests",
+			bullet:  Bullet{PullRequests: []string{"https://github.com/goflink/krisss/pull/32"}, Text: "Fixed it in #33, see [the PR](https://github.com/goflink/krisss/pull/34)."},
+			unknown: []string{"https://github.com/goflink/krisss/pull/32", "https://github.com/goflink/krisss/pull/34", "#33"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bullet := tt.bullet
+			err := testKnown().Verify(&Entry{Bullets: []*Bullet{&bullet}})
+			if len(tt.unknown) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			var unknown *UnknownReferencesError
+			require.ErrorAs(t, err, &unknown)
+			assert.Equal(t, map[int][]string{0: tt.unknown}, unknown.References)
+		})
+	}
+}
+
+func TestGuard(t *testing.T) {
+	newEntry := func() *Entry {
+		return &Entry{Bullets: []*Bullet{
+			{Category: "feature", Ticket: "DX-999", PullRequests: []string{"https://github.com/goflink/krisss/pull/31", "https://github.com/goflink/krisss/pull/32"},
+				Text: "Added a flake (DX-998) and fixed #33, see [the docs](https://example.com/docs)."},
+			{Category: "review", PullRequests: []string{"https://github.com/goflink/platform-repo-templates/pull/748"}, Text: "Reviewed templates"},
+		}}
+	}
+
+	flagged := newEntry()
+	testKnown().Flag(flagged)
+	var out bytes.Buffer
+	flagged.Render(&out, "06.Jun.2025", nil)
+	assert.Equal(t, `06.Jun.2025
+- DX-999: Added a flake (DX-998) and fixed #33, see [the docs](https://example.com/docs). See PRs [#31](https://github.com/goflink/krisss/pull/31) and [#32](https://github.com/goflink/krisss/pull/32). (unverified: DX-999, https://github.com/goflink/krisss/pull/32, https://example.com/docs, #33, DX-998)
+- Reviewed templates. See PR [#748](https://github.com/goflink/platform-repo-templates/pull/748).
+`, out.String())
+
+	stripped := newEntry()
+	testKnown().Strip(stripped)
+	assert.NoError(t, testKnown().Verify(stripped))
+	out.Reset()
+	stripped.Render(&out, "06.Jun.2025", nil)
+	assert.Equal(t, `06.Jun.2025
+- Added a flake and fixed, see the docs. See PR [#31](https://github.com/goflink/krisss/pull/31).
+- Reviewed templates. See PR [#748](https://github.com/goflink/platform-repo-templates/pull/748).
+`, out.String())
+}
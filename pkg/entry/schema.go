@@ -0,0 +1,138 @@
+package entry
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// schemaNode is the subset of JSON schema that schema.json uses.
+type schemaNode struct {
+	Type                 string                 `json:"type"`
+	Enum                 []string               `json:"enum"`
+	Pattern              string                 `json:"pattern"`
+	MinLength            int                    `json:"minLength"`
+	Required             []string               `json:"required"`
+	AdditionalProperties *bool                  `json:"additionalProperties"`
+	Properties           map[string]*schemaNode `json:"properties"`
+	Items                *schemaNode            `json:"items"`
+
+	pattern *regexp.Regexp
+}
+
+// parseSchema decodes the schema and compiles its patterns.
+func parseSchema(data string) (*schemaNode, error) {
+	var root schemaNode
+	if err := json.Unmarshal([]byte(data), &root); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	if err := root.compile(); err != nil {
+		return nil, err
+	}
+	return &root, nil
+}
+
+func (n *schemaNode) compile() error {
+	if n.Pattern != "" {
+		pattern, err := regexp.Compile(n.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern in schema: %w", err)
+		}
+		n.pattern = pattern
+	}
+	for _, child := range n.Properties {
+		if err := child.compile(); err != nil {
+			return err
+		}
+	}
+	if n.Items != nil {
+		return n.Items.compile()
+	}
+	return nil
+}
+
+// validate checks a decoded JSON value against the node and returns one error
+// per violation, naming the value by its path, e.g. "bullets[0].ticket".
+func (n *schemaNode) validate(path string, value any) []error {
+	name := path
+	if name == "" {
+		name = "answer"
+	}
+
+	if len(n.Enum) > 0 {
+		s, ok := value.(string)
+		if !ok || !slices.Contains(n.Enum, s) {
+			return []error{fmt.Errorf("%s %s is not one of %s", name, format(value), strings.Join(n.Enum, ", "))}
+		}
+	}
+
+	switch n.Type {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return []error{fmt.Errorf("%s is not an object", name)}
+		}
+		return n.validateObject(path, object)
+	case "array":
+		array, ok := value.([]any)
+		if !ok {
+			return []error{fmt.Errorf("%s is not an array", name)}
+		}
+		errs := []error{}
+		if n.Items != nil {
+			for i, item := range array {
+				errs = append(errs, n.Items.validate(fmt.Sprintf("%s[%d]", path, i), item)...)
+			}
+		}
+		return errs
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return []error{fmt.Errorf("%s is not a string", name)}
+		}
+		if utf8.RuneCountInString(s) < n.MinLength {
+			return []error{fmt.Errorf("%s is shorter than %d characters", name, n.MinLength)}
+		}
+		if n.pattern != nil && !n.pattern.MatchString(s) {
+			return []error{fmt.Errorf("%s %q does not match the pattern %s", name, s, n.Pattern)}
+		}
+	}
+	return nil
+}
+
+func (n *schemaNode) validateObject(path string, object map[string]any) []error {
+	name, prefix := "answer", ""
+	if path != "" {
+		name, prefix = path, path+"."
+	}
+
+	errs := []error{}
+	for _, key := range n.Required {
+		if _, ok := object[key]; !ok {
+			errs = append(errs, fmt.Errorf("%s%s is missing", prefix, key))
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(object)) {
+		child, ok := n.Properties[key]
+		if !ok {
+			if n.AdditionalProperties != nil && !*n.AdditionalProperties {
+				errs = append(errs, fmt.Errorf("%s has unknown field %q", name, key))
+			}
+			continue
+		}
+		errs = append(errs, child.validate(prefix+key, object[key])...)
+	}
+	return errs
+}
+
+func format(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
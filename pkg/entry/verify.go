@@ -0,0 +1,303 @@
+package entry
+
+import (
+	"fmt"
+	"maps"
+	"perf/pkg/gh"
+	"perf/pkg/jirautils"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// GuardFlag marks unknown references in the rendered entry
+	GuardFlag = "flag"
+	// GuardStrip removes unknown references from the entry
+	GuardStrip = "strip"
+	// GuardOff leaves the entry as the model wrote it
+	GuardOff = "off"
+)
+
+var (
+	urlPattern       = regexp.MustCompile(`https?://[^\s<>()\[\]]+`)
+	markdownLink     = regexp.MustCompile(`\[([^\]]*)\]\((https?://[^\s)]+)\)`)
+	numberPattern    = regexp.MustCompile(`(^|[^\w/&])#([0-9]+)\b`)
+	repoRefPattern   = regexp.MustCompile(`(^|[^\w/.-])([\w.-]+/[\w.-]+)#([0-9]+)\b`)
+	repoURLPattern   = regexp.MustCompile(`^https?://github\.com/([^/]+/[^/]+)/`)
+	keyPattern       = regexp.MustCompile(`\b([A-Z][A-Z0-9]+)-[0-9]+\b`)
+	browsePattern    = regexp.MustCompile(`/browse/([A-Z][A-Z0-9]+-[0-9]+)$`)
+	trailingPunct    = ".,;:!?'\""
+	emptyParentheses = regexp.MustCompile(`\(\s*[,;]?\s*\)`)
+	spaces           = regexp.MustCompile(`\s{2,}`)
+)
+
+// termPrefixes are the prefixes of terms that look like ticket keys, e.g.
+// "UTF-8" or "SHA-256". Keys with these prefixes are not checked.
+var termPrefixes = map[string]bool{
+	"AES": true, "CVE": true, "HTTP": true, "ISO": true, "RFC": true, "SHA": true, "UTF": true,
+}
+
+// Known are the tickets and pull requests collected for the report. An
+// entry may only reference them.
+type Known struct {
+	keys map[string]bool
+	urls map[string]bool
+	// numbers are the issue and pull request numbers by "owner/repo"
+	numbers map[string]map[int]bool
+}
+
+func NewKnown(tickets []*jirautils.Ticket, prs []*gh.PullRequest) *Known {
+	k := Known{keys: map[string]bool{}, urls: map[string]bool{}, numbers: map[string]map[int]bool{}}
+	for _, ticket := range tickets {
+		k.AddKeys(ticket.Key)
+		k.addURL(ticket.URL)
+		for _, link := range ticket.Links {
+			k.AddKeys(link.Key)
+		}
+		for _, ref := range []*jirautils.TicketRef{ticket.Parent, ticket.Epic} {
+			if ref != nil {
+				k.AddKeys(ref.Key)
+			}
+		}
+		for _, pr := range ticket.PullRequests {
+			k.addPullRequest(pr)
+		}
+	}
+	for _, pr := range prs {
+		k.addPullRequest(pr)
+	}
+	return &k
+}
+
+// AddKeys adds ticket keys that were collected without their ticket, e.g.
+// from commit messages.
+func (k *Known) AddKeys(keys ...string) {
+	for _, key := range keys {
+		if match := keyPattern.FindStringSubmatch(key); match != nil && match[0] == key {
+			k.keys[key] = true
+		}
+	}
+}
+
+func (k *Known) addURL(url string) {
+	if url != "" {
+		k.urls[strings.TrimRight(url, "/")] = true
+	}
+}
+
+func (k *Known) addPullRequest(pr *gh.PullRequest) {
+	k.addNumber(pr.Owner, pr.Repo, pr.Number)
+	k.addURL(pr.HTMLURL)
+	k.addURL(pr.URL)
+	for _, ref := range slices.Concat(pr.LinkedIssues, pr.RelatedPullRequests) {
+		k.addNumber(ref.Owner, ref.Repo, ref.Number)
+		k.addURL(ref.URL)
+	}
+}
+
+func (k *Known) addNumber(owner, repo string, number int) {
+	name := strings.ToLower(owner + "/" + repo)
+	if k.numbers[name] == nil {
+		k.numbers[name] = map[int]bool{}
+	}
+	k.numbers[name][number] = true
+}
+
+// knownNumber tells whether #number is known in one of the repositories. It
+// is unknown if no repository is given.
+func (k *Known) knownNumber(repos []string, number int) bool {
+	for _, repo := range repos {
+		if k.numbers[strings.ToLower(repo)][number] {
+			return true
+		}
+	}
+	return false
+}
+
+// bulletRepos are the repositories of the pull requests of the bullet. A bare
+// #number in its text refers to one of them, so it is unknown in a bullet
+// without pull requests.
+func bulletRepos(b *Bullet) []string {
+	repos := []string{}
+	for _, url := range b.PullRequests {
+		if match := repoURLPattern.FindStringSubmatch(url); match != nil && !slices.Contains(repos, match[1]) {
+			repos = append(repos, match[1])
+		}
+	}
+	return repos
+}
+
+func (k *Known) knownURL(url string) bool {
+	url = strings.TrimRight(url, "/")
+	for known := range k.urls {
+		if url == known || strings.HasPrefix(url, known+"/") || strings.HasPrefix(url, known+"#") {
+			return true
+		}
+	}
+	if match := browsePattern.FindStringSubmatch(url); match != nil {
+		return k.keys[match[1]]
+	}
+	return false
+}
+
+// knownKey tells whether a ticket key in a text is known. Terms like "UTF-8"
+// are not checked.
+func (k *Known) knownKey(key string) bool {
+	match := keyPattern.FindStringSubmatch(key)
+	return match == nil || termPrefixes[match[1]] || k.keys[key]
+}
+
+// UnknownReferencesError lists the references of an entry to tickets and
+// pull requests that were not collected.
+type UnknownReferencesError struct {
+	// References are the unknown references by bullet index
+	References map[int][]string
+}
+
+func (e *UnknownReferencesError) Error() string {
+	bullets := []string{}
+	for _, i := range slices.Sorted(maps.Keys(e.References)) {
+		bullets = append(bullets, fmt.Sprintf("bullets[%d] references %s", i, strings.Join(e.References[i], ", ")))
+	}
+	return fmt.Sprintf("unknown tickets or pull requests, only use those of the input: %s", strings.Join(bullets, "; "))
+}
+
+// Verify checks the ticket, the pull requests and every URL, owner/repo#number,
+// #number and ticket key in the text of each bullet. A bare #number must
+// belong to the repository of one of the pull requests of the bullet, so it is
+// unknown in a bullet without pull requests. It returns an
+// *UnknownReferencesError if any of them was not collected.
+func (k *Known) Verify(e *Entry) error {
+	references := map[int][]string{}
+	for i, b := range e.Bullets {
+		unknown := k.unknown(b)
+		if len(unknown) > 0 {
+			references[i] = unknown
+		}
+	}
+	if len(references) == 0 {
+		return nil
+	}
+	return &UnknownReferencesError{References: references}
+}
+
+func (k *Known) unknown(b *Bullet) []string {
+	unknown := []string{}
+	add := func(ref string) {
+		if !slices.Contains(unknown, ref) {
+			unknown = append(unknown, ref)
+		}
+	}
+
+	if b.Ticket != "" && !k.keys[b.Ticket] {
+		add(b.Ticket)
+	}
+	for _, url := range b.PullRequests {
+		if !k.knownURL(url) {
+			add(url)
+		}
+	}
+
+	urls := urlPattern.FindAllString(b.Text, -1)
+	for _, url := range urls {
+		url = strings.TrimRight(url, trailingPunct)
+		if !k.knownURL(url) {
+			add(url)
+		}
+	}
+	text := urlPattern.ReplaceAllString(b.Text, " ")
+	for _, match := range repoRefPattern.FindAllStringSubmatch(text, -1) {
+		number, _ := strconv.Atoi(match[3])
+		if !k.knownNumber([]string{match[2]}, number) {
+			add(match[2] + "#" + match[3])
+		}
+	}
+	repos := bulletRepos(b)
+	for _, match := range numberPattern.FindAllStringSubmatch(text, -1) {
+		number, _ := strconv.Atoi(match[2])
+		if !k.knownNumber(repos, number) {
+			add("#" + match[2])
+		}
+	}
+	for _, key := range keyPattern.FindAllString(text, -1) {
+		if !k.knownKey(key) {
+			add(key)
+		}
+	}
+	return unknown
+}
+
+// Flag marks every bullet with its unknown references.
+func (k *Known) Flag(e *Entry) {
+	for _, b := range e.Bullets {
+		b.Unverified = k.unknown(b)
+		if len(b.Unverified) == 0 {
+			b.Unverified = nil
+		}
+	}
+}
+
+// Strip removes the unknown references: an unknown ticket or pull request of
+// a bullet, links to unknown URLs (keeping their text), and unknown URLs,
+// owner/repo#numbers, #numbers and ticket keys in the text.
+func (k *Known) Strip(e *Entry) {
+	for _, b := range e.Bullets {
+		if b.Ticket != "" && !k.keys[b.Ticket] {
+			b.Ticket = ""
+		}
+
+		prs := []string{}
+		for _, url := range b.PullRequests {
+			if k.knownURL(url) {
+				prs = append(prs, url)
+			}
+		}
+		b.PullRequests = prs
+		if len(b.PullRequests) == 0 {
+			b.PullRequests = nil
+		}
+
+		text := markdownLink.ReplaceAllStringFunc(b.Text, func(link string) string {
+			match := markdownLink.FindStringSubmatch(link)
+			if k.knownURL(match[2]) {
+				return link
+			}
+			return match[1]
+		})
+		text = urlPattern.ReplaceAllStringFunc(text, func(url string) string {
+			trimmed := strings.TrimRight(url, trailingPunct)
+			if k.knownURL(trimmed) {
+				return url
+			}
+			return url[len(trimmed):]
+		})
+		text = repoRefPattern.ReplaceAllStringFunc(text, func(ref string) string {
+			match := repoRefPattern.FindStringSubmatch(ref)
+			if number, _ := strconv.Atoi(match[3]); k.knownNumber([]string{match[2]}, number) {
+				return ref
+			}
+			return match[1]
+		})
+		repos := bulletRepos(b)
+		text = numberPattern.ReplaceAllStringFunc(text, func(ref string) string {
+			match := numberPattern.FindStringSubmatch(ref)
+			if number, _ := strconv.Atoi(match[2]); k.knownNumber(repos, number) {
+				return ref
+			}
+			return match[1]
+		})
+		text = keyPattern.ReplaceAllStringFunc(text, func(key string) string {
+			if k.knownKey(key) {
+				return key
+			}
+			return ""
+		})
+		text = emptyParentheses.ReplaceAllString(text, "")
+		text = spaces.ReplaceAllString(text, " ")
+		text = strings.ReplaceAll(text, " .", ".")
+		text = strings.ReplaceAll(text, " ,", ",")
+		b.Text = strings.TrimSpace(text)
+	}
+}
@@ -0,0 +1,99 @@
+package gh
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v72/github"
+)
+
+const (
+	CIStatusSuccess = "success"
+	CIStatusFailure = "failure"
+	CIStatusPending = "pending"
+	CIStatusNone    = "none"
+)
+
+// checkRunStatus maps a check run onto one of the CI statuses.
+func checkRunStatus(status, conclusion string) string {
+	if status != "completed" {
+		return CIStatusPending
+	}
+	switch conclusion {
+	case "success", "neutral", "skipped":
+		return CIStatusSuccess
+	default:
+		return CIStatusFailure
+	}
+}
+
+// combineCIStatus reduces several statuses to one: any failure wins over
+// pending, pending wins over success.
+func combineCIStatus(statuses []string) string {
+	combined := CIStatusNone
+	for _, status := range statuses {
+		switch status {
+		case CIStatusFailure, "error":
+			return CIStatusFailure
+		case CIStatusPending:
+			combined = CIStatusPending
+		case CIStatusSuccess:
+			if combined == CIStatusNone {
+				combined = CIStatusSuccess
+			}
+		}
+	}
+	return combined
+}
+
+// Check is a single commit status or check run reported for a SHA.
+type Check struct {
+	Name   string
+	Status string
+}
+
+// GetChecks returns the commit statuses and the check runs reported for a SHA.
+func GetChecks(client *github.Client, ctx context.Context, owner, repo, sha string) ([]*Check, error) {
+	checks := []*Check{}
+
+	combined, _, err := client.Repositories.GetCombinedStatus(ctx, owner, repo, sha, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get combined status for %s in %s/%s: %w", sha, owner, repo, err)
+	}
+	for _, status := range combined.Statuses {
+		checks = append(checks, &Check{Name: status.GetContext(), Status: status.GetState()})
+	}
+
+	opts := &github.ListCheckRunsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	checkRuns, _, err := client.Checks.ListCheckRunsForRef(ctx, owner, repo, sha, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list check runs for %s in %s/%s: %w", sha, owner, repo, err)
+	}
+	for _, run := range checkRuns.CheckRuns {
+		checks = append(checks, &Check{Name: run.GetName(), Status: checkRunStatus(run.GetStatus(), run.GetConclusion())})
+	}
+
+	return checks, nil
+}
+
+// FetchCIStatus collects the checks of the head commit and combines them into CIStatus.
+func (pr *PullRequest) FetchCIStatus(client *github.Client, ctx context.Context) error {
+	if pr.HeadSHA == "" {
+		if err := pr.FetchDetails(client, ctx); err != nil {
+			return err
+		}
+	}
+
+	checks, err := GetChecks(client, ctx, pr.Owner, pr.Repo, pr.HeadSHA)
+	if err != nil {
+		return err
+	}
+
+	statuses := []string{}
+	for _, check := range checks {
+		statuses = append(statuses, check.Status)
+	}
+	pr.Checks = checks
+	pr.CIStatus = combineCIStatus(statuses)
+	return nil
+}
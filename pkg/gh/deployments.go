@@ -0,0 +1,90 @@
+package gh
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/go-github/v72/github"
+)
+
+// Delivery stages of a pull request, from least to most advanced.
+const (
+	StageOpened   = "opened"
+	StageMerged   = "merged"
+	StageDeployed = "deployed"
+)
+
+// Deployment is a GitHub deployment of a merge commit with its latest status.
+type Deployment struct {
+	Environment string
+	State       string
+	CreatedAt   time.Time
+}
+
+// GetDeployments returns the deployments of a SHA with the state of their
+// most recent deployment status.
+func GetDeployments(client *github.Client, ctx context.Context, owner, repo, sha string) ([]*Deployment, error) {
+	opts := &github.DeploymentsListOptions{SHA: sha, ListOptions: github.ListOptions{PerPage: 100}}
+	ghDeployments, _, err := client.Repositories.ListDeployments(ctx, owner, repo, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments for %s in %s/%s: %w", sha, owner, repo, err)
+	}
+
+	deployments := []*Deployment{}
+	for _, d := range ghDeployments {
+		deployment := Deployment{
+			Environment: d.GetEnvironment(),
+			CreatedAt:   d.GetCreatedAt().UTC(),
+		}
+
+		// statuses are returned newest first
+		statuses, _, err := client.Repositories.ListDeploymentStatuses(ctx, owner, repo, d.GetID(), &github.ListOptions{PerPage: 1})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list statuses of deployment %d in %s/%s: %w", d.GetID(), owner, repo, err)
+		}
+		if len(statuses) > 0 {
+			deployment.State = statuses[0].GetState()
+			deployment.CreatedAt = statuses[0].GetCreatedAt().UTC()
+		}
+		deployments = append(deployments, &deployment)
+	}
+	return deployments, nil
+}
+
+// deliveryStage tells whether a pull request is only opened, merged, or
+// successfully deployed to one of the target environments.
+func deliveryStage(merged bool, deployments []*Deployment, environments []string) string {
+	if !merged {
+		return StageOpened
+	}
+	for _, d := range deployments {
+		if d.State == "success" && slices.Contains(environments, d.Environment) {
+			return StageDeployed
+		}
+	}
+	return StageMerged
+}
+
+// FetchDelivery collects the CI checks of the head commit, the deployments of
+// the merge commit and derives the delivery stage of the pull request.
+func (pr *PullRequest) FetchDelivery(client *github.Client, ctx context.Context, environments []string) error {
+	if err := pr.FetchDetails(client, ctx); err != nil {
+		return err
+	}
+	if err := pr.FetchCIStatus(client, ctx); err != nil {
+		return err
+	}
+
+	if pr.MergeSHA != "" {
+		deployments, err := GetDeployments(client, ctx, pr.Owner, pr.Repo, pr.MergeSHA)
+		if err != nil {
+			return err
+		}
+		pr.Deployments = deployments
+	}
+
+	pr.Stage = deliveryStage(pr.MergeSHA != "", pr.Deployments, environments)
+	return nil
+}
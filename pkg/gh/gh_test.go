@@ -1,9 +1,16 @@
 package gh
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
 
+	"github.com/google/go-github/v72/github"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetTicket(t *testing.T) {
@@ -22,3 +29,92 @@ func TestGetTicket(t *testing.T) {
 		assert.Equal(t, tt.expected, match)
 	}
 }
+
+func TestCombineCIStatus(t *testing.T) {
+	tests := []struct {
+		input    []string
+		expected string
+	}{
+		{input: []string{}, expected: CIStatusNone},
+		{input: []string{"success", "success"}, expected: CIStatusSuccess},
+		{input: []string{"success", "pending"}, expected: CIStatusPending},
+		{input: []string{"pending", "failure", "success"}, expected: CIStatusFailure},
+		{input: []string{"error"}, expected: CIStatusFailure},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, combineCIStatus(tt.input))
+	}
+}
+
+func TestCheckRunStatus(t *testing.T) {
+	assert.Equal(t, CIStatusPending, checkRunStatus("in_progress", ""))
+	assert.Equal(t, CIStatusSuccess, checkRunStatus("completed", "skipped"))
+	assert.Equal(t, CIStatusFailure, checkRunStatus("completed", "timed_out"))
+}
+
+func TestDeliveryStage(t *testing.T) {
+	environments := []string{"production"}
+	tests := []struct {
+		merged      bool
+		deployments []*Deployment
+		expected    string
+	}{
+		{merged: false, expected: StageOpened},
+		{merged: true, expected: StageMerged},
+		{merged: true, deployments: []*Deployment{{Environment: "staging", State: "success"}}, expected: StageMerged},
+		{merged: true, deployments: []*Deployment{{Environment: "production", State: "failure"}}, expected: StageMerged},
+		{merged: true, deployments: []*Deployment{{Environment: "production", State: "success"}}, expected: StageDeployed},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, deliveryStage(tt.merged, tt.deployments, environments))
+	}
+}
+
+func TestParseReferences(t *testing.T) {
+	body := `Closes #123 and fixes goflink/dunebot#7.
+Related to #45, see https://github.com/goflink/krisss/pull/31 for context.
+Also resolved: https://github.com/goflink/perf/issues/9. Mentioning #123 again.`
+
+	refs := parseReferences(body, "goflink", "perf")
+	expected := []IssueRef{
+		{Owner: "goflink", Repo: "perf", Number: 123, Closes: true},
+		{Owner: "goflink", Repo: "dunebot", Number: 7, Closes: true},
+		{Owner: "goflink", Repo: "perf", Number: 9, Closes: true},
+		{Owner: "goflink", Repo: "perf", Number: 45},
+		{Owner: "goflink", Repo: "krisss", Number: 31},
+	}
+	assert.Len(t, refs, len(expected))
+	for i, ref := range refs {
+		assert.Equal(t, expected[i], *ref)
+	}
+}
+
+func TestParseReferencesWithoutReferences(t *testing.T) {
+	refs := parseReferences("## Summary\nNo issue here, closes nothing.", "goflink", "perf")
+	assert.Empty(t, refs)
+
+	refs = parseReferences("See page#3, docs/a/b#12, a/b#12, https://example.com/setup#4 and #12a.", "goflink", "perf")
+	assert.Empty(t, refs)
+}
+
+func TestFetchLinksSkipsUnresolvable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/goflink/perf/issues/45" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"number": 45, "title": "Flaky CI", "state": "open", "html_url": "https://github.com/goflink/perf/issues/45", "repository_url": "https://api.github.com/repos/goflink/perf"}`)
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	baseURL, err := url.Parse(server.URL + "/")
+	require.NoError(t, err)
+	client.BaseURL = baseURL
+
+	pr := &PullRequest{Owner: "goflink", Repo: "perf", Number: 50, Description: "Fixes #1, related to #45", Timeline: []*TimelineEvent{}}
+	require.NoError(t, pr.FetchLinks(client, context.Background()))
+	require.Len(t, pr.LinkedIssues, 1)
+	assert.Equal(t, 45, pr.LinkedIssues[0].Number)
+}
@@ -19,6 +19,10 @@ type Commit struct {
 	Timestamp time.Time
 	Files     []*CommitFile
 	Message   string
+	// Repo, Branch and Ticket are only known for commits read from local clones
+	Repo   string `json:",omitempty"`
+	Branch string `json:",omitempty"`
+	Ticket string `json:",omitempty"`
 }
 
 type CommitFile struct {
@@ -51,14 +55,31 @@ type PullRequest struct {
 	Repo        string
 	Author      string
 	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	MergedAt    time.Time
+	State       string
 	Description string
 	Title       string
 	URL         string
+	HTMLURL     string
 	Commits     []*Commit
 	Ticket      string
 	Created     bool
 	Updated     bool
 	Reviewed    bool
+	Draft       bool
+	HeadSHA     string
+	Additions   int
+	Deletions   int
+	CIStatus    string
+	Checks      []*Check
+	MergeSHA    string
+	Deployments []*Deployment
+	Stage       string
+	// LinkedIssues and RelatedPullRequests are referenced from the description or cross-referenced elsewhere
+	LinkedIssues        []*IssueRef
+	RelatedPullRequests []*IssueRef
+	Timeline            []*TimelineEvent `json:"-"`
 }
 
 type ReviewsByPullRequest struct {
@@ -128,9 +149,13 @@ func NewPullRequest(
 		Repo:        repo,
 		Author:      pr.GetUser().GetLogin(),
 		CreatedAt:   pr.GetCreatedAt().UTC(),
+		UpdatedAt:   pr.GetUpdatedAt().UTC(),
+		MergedAt:    pr.GetPullRequestLinks().GetMergedAt().UTC(),
+		State:       pr.GetState(),
 		Description: pr.GetBody(),
 		Title:       pr.GetTitle(),
 		URL:         pr.GetURL(),
+		HTMLURL:     pr.GetHTMLURL(),
 		Ticket:      ticketID,
 	}
 
@@ -150,6 +175,24 @@ func (pr *PullRequest) FetchCommits(client *github.Client, ctx context.Context,
 	return nil
 }
 
+// FetchDetails fills in the fields that are only returned by the pull request
+// endpoint and not by the search API (head SHA, merge commit, size, draft state).
+func (pr *PullRequest) FetchDetails(client *github.Client, ctx context.Context) error {
+	ghPR, _, err := client.PullRequests.Get(ctx, pr.Owner, pr.Repo, pr.Number)
+	if err != nil {
+		return fmt.Errorf("failed to get PR %d in %s/%s: %w", pr.Number, pr.Owner, pr.Repo, err)
+	}
+
+	pr.Draft = ghPR.GetDraft()
+	pr.HeadSHA = ghPR.GetHead().GetSHA()
+	pr.Additions = ghPR.GetAdditions()
+	pr.Deletions = ghPR.GetDeletions()
+	if ghPR.GetMerged() {
+		pr.MergeSHA = ghPR.GetMergeCommitSHA()
+	}
+	return nil
+}
+
 func (pr *PullRequest) FetchComments(client *github.Client, ctx context.Context) ([]*github.IssueComment, error) {
 	comments, _, err := client.Issues.ListComments(ctx, pr.Owner, pr.Repo, pr.Number, nil)
 	if err != nil {
@@ -197,6 +240,7 @@ func (pr *PullRequest) String(verbose bool) string {
 	// Conditionally remove fields
 	if !verbose {
 		delete(result, "Commits")
+		delete(result, "Checks")
 	}
 
 	data, _ := json.MarshalIndent(result, "", "  ")
@@ -483,6 +527,11 @@ func getOwner(url string) string {
 	return owner
 }
 
+// FindTicket returns the first Jira ticket key found in the text.
+func FindTicket(text string) string {
+	return getTicket(text)
+}
+
 func getTicket(PRtitle string) string {
 	re := regexp.MustCompile(`[A-Z]{2,}\d{0,}-\d+`)
 	match := re.Find([]byte(PRtitle))
@@ -0,0 +1,141 @@
+package gh
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-github/v72/github"
+)
+
+// IssueRef is a GitHub issue or pull request referenced by a pull request.
+type IssueRef struct {
+	Owner         string
+	Repo          string
+	Number        int
+	Title         string
+	State         string
+	URL           string
+	IsPullRequest bool
+	// Closes is set when the reference uses a closing keyword ("Closes #123")
+	Closes bool
+}
+
+func (r *IssueRef) key() string {
+	return CreateMapKey(r.Owner, r.Repo, r.Number)
+}
+
+var (
+	// #123, owner/repo#123 or https://github.com/owner/repo/(issues|pull)/123
+	refPattern = `(?:https://github\.com/([\w.-]+)/([\w.-]+)/(?:issues|pull)/(\d+)|(?:([\w.-]+)/([\w.-]+))?#(\d+))\b`
+	closingRe  = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?):?\s+` + refPattern)
+	// references start the text or follow a space or an opening bracket, so
+	// that anchors such as page#3 or docs/a/b#12 are left alone
+	referenceRe = regexp.MustCompile(`(?:^|[\s(\[{,;:])` + refPattern)
+)
+
+// parseReferences extracts issue and pull request references from a pull
+// request body. References without an owner/repo resolve to the given repo;
+// owner/repo#123 is only a reference within the owner, elsewhere it is more
+// likely a path with an anchor.
+func parseReferences(body, owner, repo string) []*IssueRef {
+	refs := []*IssueRef{}
+	seen := map[string]*IssueRef{}
+
+	add := func(match []string, closes bool) {
+		ref := IssueRef{Owner: owner, Repo: repo, Closes: closes}
+		switch {
+		case match[3] != "":
+			ref.Owner, ref.Repo = match[1], match[2]
+			ref.Number, _ = strconv.Atoi(match[3])
+		case match[4] != "":
+			if !strings.EqualFold(match[4], owner) {
+				return
+			}
+			ref.Owner, ref.Repo = match[4], match[5]
+			ref.Number, _ = strconv.Atoi(match[6])
+		default:
+			ref.Number, _ = strconv.Atoi(match[6])
+		}
+
+		if existing, ok := seen[ref.key()]; ok {
+			existing.Closes = existing.Closes || closes
+			return
+		}
+		seen[ref.key()] = &ref
+		refs = append(refs, &ref)
+	}
+
+	for _, match := range closingRe.FindAllStringSubmatch(body, -1) {
+		add(match, true)
+	}
+	for _, match := range referenceRe.FindAllStringSubmatch(body, -1) {
+		add(match, false)
+	}
+	return refs
+}
+
+func newIssueRef(issue *github.Issue) *IssueRef {
+	return &IssueRef{
+		Owner:         getOwner(issue.GetRepositoryURL()),
+		Repo:          getRepoName(issue.GetRepositoryURL()),
+		Number:        issue.GetNumber(),
+		Title:         issue.GetTitle(),
+		State:         issue.GetState(),
+		URL:           issue.GetHTMLURL(),
+		IsPullRequest: issue.IsPullRequest(),
+	}
+}
+
+// FetchLinks resolves the issues and pull requests referenced in the
+// description and in cross-reference events of the timeline. References that
+// cannot be resolved, e.g. to private repositories, are skipped.
+func (pr *PullRequest) FetchLinks(client *github.Client, ctx context.Context) error {
+	if pr.Timeline == nil {
+		if err := pr.FetchTimeline(client, ctx); err != nil {
+			return err
+		}
+	}
+
+	refs := parseReferences(pr.Description, pr.Owner, pr.Repo)
+	seen := map[string]bool{CreateMapKey(pr.Owner, pr.Repo, pr.Number): true}
+	links := []*IssueRef{}
+
+	for _, ref := range refs {
+		if seen[ref.key()] {
+			continue
+		}
+		seen[ref.key()] = true
+
+		issue, _, err := client.Issues.Get(ctx, ref.Owner, ref.Repo, ref.Number)
+		if err != nil {
+			slog.Warn("skipping unresolvable reference", slog.String("pr", CreateMapKey(pr.Owner, pr.Repo, pr.Number)),
+				slog.String("reference", ref.key()), slog.String("error", err.Error()))
+			continue
+		}
+		resolved := newIssueRef(issue)
+		resolved.Closes = ref.Closes
+		links = append(links, resolved)
+	}
+
+	for _, e := range pr.Timeline {
+		if e.Event != "cross-referenced" || e.Source == nil || seen[e.Source.key()] {
+			continue
+		}
+		seen[e.Source.key()] = true
+		links = append(links, e.Source)
+	}
+
+	pr.LinkedIssues = []*IssueRef{}
+	pr.RelatedPullRequests = []*IssueRef{}
+	for _, link := range links {
+		if link.IsPullRequest {
+			pr.RelatedPullRequests = append(pr.RelatedPullRequests, link)
+		} else {
+			pr.LinkedIssues = append(pr.LinkedIssues, link)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package gh
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v72/github"
+)
+
+// TimelineEvent is a condensed entry of a pull request's issue timeline.
+type TimelineEvent struct {
+	Event     string
+	Actor     string
+	Reviewer  string
+	Team      string
+	State     string
+	CreatedAt time.Time
+	// Source is the referencing issue or PR of a "cross-referenced" event
+	Source *IssueRef
+}
+
+func newTimelineEvent(e *github.Timeline) *TimelineEvent {
+	event := TimelineEvent{
+		Event:    e.GetEvent(),
+		Actor:    e.GetActor().GetLogin(),
+		Reviewer: e.GetReviewer().GetLogin(),
+		Team:     e.GetRequestedTeam().GetSlug(),
+		State:    e.GetState(),
+	}
+
+	switch event.Event {
+	case "reviewed":
+		// reviews carry the author in `user` and the time in `submitted_at`
+		event.Actor = e.GetUser().GetLogin()
+		event.CreatedAt = e.GetSubmittedAt().UTC()
+	case "committed":
+		event.Actor = e.GetAuthor().GetName()
+		event.CreatedAt = e.GetAuthor().GetDate().UTC()
+	case "cross-referenced":
+		event.CreatedAt = e.GetCreatedAt().UTC()
+		if issue := e.GetSource().GetIssue(); issue != nil {
+			event.Source = newIssueRef(issue)
+		}
+	default:
+		event.CreatedAt = e.GetCreatedAt().UTC()
+	}
+	return &event
+}
+
+func (pr *PullRequest) FetchTimeline(client *github.Client, ctx context.Context) error {
+	opts := &github.ListOptions{PerPage: 100}
+	events := []*TimelineEvent{}
+	for {
+		page, resp, err := client.Issues.ListIssueTimeline(ctx, pr.Owner, pr.Repo, pr.Number, opts)
+		if err != nil {
+			return fmt.Errorf("failed to get timeline for PR %d in %s/%s: %w", pr.Number, pr.Owner, pr.Repo, err)
+		}
+		for _, e := range page {
+			events = append(events, newTimelineEvent(e))
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	pr.Timeline = events
+	return nil
+}
+
+// OrgQualifier builds the search qualifier matching any of the given orgs.
+func OrgQualifier(orgs []string) string {
+	qualifiers := []string{}
+	for _, org := range orgs {
+		qualifiers = append(qualifiers, "org:"+org)
+	}
+	return strings.Join(qualifiers, " ")
+}
+
+// SearchPullRequests runs a search query and returns lightweight pull requests
+// without commits. Every page of the search result is consumed.
+func SearchPullRequests(client *github.Client, ctx context.Context, query Query) ([]*PullRequest, error) {
+	opts := &github.SearchOptions{Sort: "created", Order: "desc", ListOptions: github.ListOptions{PerPage: 100}}
+
+	pullRequests := []*PullRequest{}
+	for {
+		result, resp, err := client.Search.Issues(ctx, query.Query, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search for PRs with query %s: %w", query.Query, err)
+		}
+
+		for _, issue := range result.Issues {
+			if alreadyExists(pullRequests, issue.GetID()) {
+				continue
+			}
+			pr, err := NewPullRequest(client, ctx, issue, query.Name, "", getTicket(issue.GetTitle()))
+			if err != nil {
+				return nil, fmt.Errorf("failed to instantiate a *PullRequest: %w", err)
+			}
+			pullRequests = append(pullRequests, pr)
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	return pullRequests, nil
+}
@@ -0,0 +1,117 @@
+package identity
+
+import (
+	"perf/pkg/config"
+	"perf/pkg/gh"
+	"perf/pkg/jirautils"
+	"sort"
+
+	"github.com/google/go-github/v72/github"
+)
+
+// Directory links GitHub logins and Jira accounts of the same person so that
+// both appear under one name in the report.
+type Directory struct {
+	byGitHub map[string]*config.Person
+	byJira   map[string]*config.Person
+}
+
+func NewDirectory(people []config.Person) *Directory {
+	d := Directory{byGitHub: map[string]*config.Person{}, byJira: map[string]*config.Person{}}
+	for _, person := range people {
+		d.Add(person)
+	}
+	return &d
+}
+
+// Add registers a person unless the GitHub login or the Jira account is
+// already known. A person without a name is named after the GitHub login.
+func (d *Directory) Add(person config.Person) {
+	if _, ok := d.byGitHub[person.GitHub]; ok && person.GitHub != "" {
+		return
+	}
+	if _, ok := d.byJira[person.JiraAccountID]; ok && person.JiraAccountID != "" {
+		return
+	}
+	if person.Name == "" {
+		person.Name = person.GitHub
+	}
+
+	p := &person
+	if p.GitHub != "" {
+		d.byGitHub[p.GitHub] = p
+	}
+	if p.JiraAccountID != "" {
+		d.byJira[p.JiraAccountID] = p
+	}
+}
+
+// GitHubName returns the name of the person with the login, or the login
+// itself if it is unknown.
+func (d *Directory) GitHubName(login string) string {
+	if person, ok := d.byGitHub[login]; ok {
+		return person.Name
+	}
+	return login
+}
+
+// JiraName returns the name of the person with the account, or fallback if
+// the account is unknown.
+func (d *Directory) JiraName(accountID, fallback string) string {
+	if person, ok := d.byJira[accountID]; ok {
+		return person.Name
+	}
+	return fallback
+}
+
+// ApplyToTicket replaces the author names of comments and worklogs with the
+// names of the directory.
+func (d *Directory) ApplyToTicket(ticket *jirautils.Ticket) {
+	for _, c := range ticket.Comments {
+		c.Author = d.JiraName(c.AuthorAccountID, c.Author)
+	}
+	for _, w := range ticket.Worklogs {
+		w.Author = d.JiraName(w.AuthorAccountID, w.Author)
+	}
+}
+
+// ApplyToReviews sets the name of every reviewer and commenter, leaving the
+// logins untouched.
+func (d *Directory) ApplyToReviews(reviews *gh.ReviewsByPullRequest) {
+	for _, review := range reviews.Reviews {
+		d.applyToUser(review.Summary.GetUser())
+		for _, c := range review.Comments {
+			d.applyToUser(c.GetUser())
+		}
+	}
+	for _, c := range reviews.Comments {
+		d.applyToUser(c.GetUser())
+	}
+}
+
+func (d *Directory) applyToUser(user *github.User) {
+	if user == nil {
+		return
+	}
+	if person, ok := d.byGitHub[user.GetLogin()]; ok {
+		user.Name = github.Ptr(person.Name)
+	}
+}
+
+// People returns every known person, sorted by name.
+func (d *Directory) People() []config.Person {
+	people := map[*config.Person]bool{}
+	for _, person := range d.byGitHub {
+		people[person] = true
+	}
+	for _, person := range d.byJira {
+		people[person] = true
+	}
+
+	sorted := []config.Person{}
+	for person := range people {
+		sorted = append(sorted, *person)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	return sorted
+}
@@ -0,0 +1,62 @@
+package identity
+
+import (
+	"perf/pkg/config"
+	"perf/pkg/gh"
+	"perf/pkg/jirautils"
+	"testing"
+
+	"github.com/google/go-github/v72/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDirectory(t *testing.T) {
+	d := NewDirectory([]config.Person{
+		{Name: "Frank Ittermann", GitHub: "frank-i", JiraAccountID: "712020:abc"},
+		{GitHub: "bot-only"},
+	})
+	// already configured accounts are not overridden
+	d.Add(config.Person{Name: "Frank", GitHub: "frank-i", JiraAccountID: "712020:other"})
+	d.Add(config.Person{Name: "Kristina Pianykh", GitHub: "Kristina-Pianykh", JiraAccountID: "712020:def"})
+
+	assert.Equal(t, "Frank Ittermann", d.GitHubName("frank-i"))
+	assert.Equal(t, "bot-only", d.GitHubName("bot-only"))
+	assert.Equal(t, "unknown", d.GitHubName("unknown"))
+	assert.Equal(t, "Kristina Pianykh", d.JiraName("712020:def", ""))
+	assert.Equal(t, "Jira Name", d.JiraName("712020:other", "Jira Name"))
+
+	assert.Equal(t, []config.Person{
+		{Name: "Frank Ittermann", GitHub: "frank-i", JiraAccountID: "712020:abc"},
+		{Name: "Kristina Pianykh", GitHub: "Kristina-Pianykh", JiraAccountID: "712020:def"},
+		{Name: "bot-only", GitHub: "bot-only"},
+	}, d.People())
+}
+
+func TestApply(t *testing.T) {
+	d := NewDirectory([]config.Person{{Name: "Frank Ittermann", GitHub: "frank-i", JiraAccountID: "712020:abc"}})
+
+	ticket := jirautils.Ticket{
+		Comments: []*jirautils.Comment{
+			{Author: "frank", AuthorAccountID: "712020:abc"},
+			{Author: "Someone", AuthorAccountID: "712020:xyz"},
+		},
+		Worklogs: []*jirautils.Worklog{{Author: "frank", AuthorAccountID: "712020:abc"}},
+	}
+	d.ApplyToTicket(&ticket)
+	assert.Equal(t, "Frank Ittermann", ticket.Comments[0].Author)
+	assert.Equal(t, "Someone", ticket.Comments[1].Author)
+	assert.Equal(t, "Frank Ittermann", ticket.Worklogs[0].Author)
+
+	reviews := gh.ReviewsByPullRequest{
+		Reviews: []*gh.Review{{
+			Summary:  &github.PullRequestReview{User: &github.User{Login: github.Ptr("frank-i")}},
+			Comments: []*github.PullRequestComment{{User: &github.User{Login: github.Ptr("someone")}}},
+		}},
+		Comments: []*github.IssueComment{{User: &github.User{Login: github.Ptr("frank-i")}}, {}},
+	}
+	d.ApplyToReviews(&reviews)
+	assert.Equal(t, "Frank Ittermann", reviews.Reviews[0].Summary.GetUser().GetName())
+	assert.Equal(t, "frank-i", reviews.Reviews[0].Summary.GetUser().GetLogin())
+	assert.Equal(t, "", reviews.Reviews[0].Comments[0].GetUser().GetName())
+	assert.Equal(t, "Frank Ittermann", reviews.Comments[0].GetUser().GetName())
+}
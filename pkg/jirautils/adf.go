@@ -0,0 +1,336 @@
+package jirautils
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// ADFNode is a node of an Atlassian Document Format document, the rich text
+// format of descriptions and comments in the Jira Cloud v3 API.
+type ADFNode struct {
+	Type    string         `json:"type"`
+	Text    string         `json:"text,omitempty"`
+	Attrs   map[string]any `json:"attrs,omitempty"`
+	Marks   []*ADFMark     `json:"marks,omitempty"`
+	Content []*ADFNode     `json:"content,omitempty"`
+}
+
+type ADFMark struct {
+	Type  string         `json:"type"`
+	Attrs map[string]any `json:"attrs,omitempty"`
+}
+
+// UserResolver returns the display name of a Jira account.
+type UserResolver func(accountID string) string
+
+func (n *ADFNode) attr(key string) string {
+	switch v := n.Attrs[key].(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return ""
+	}
+}
+
+// ADFToMarkdown renders an ADF document as Markdown. Mentions without a
+// display text are resolved with resolve, which may be nil.
+func ADFToMarkdown(doc *ADFNode, resolve UserResolver) string {
+	if doc == nil {
+		return ""
+	}
+	r := adfRenderer{resolve: resolve}
+	return strings.TrimSpace(r.blocks(doc.Content, ""))
+}
+
+type adfRenderer struct {
+	resolve UserResolver
+}
+
+// blocks renders block nodes separated by blank lines, every line prefixed
+// with indent.
+func (r *adfRenderer) blocks(nodes []*ADFNode, indent string) string {
+	parts := []string{}
+	for _, n := range nodes {
+		if block := r.block(n, indent); block != "" {
+			parts = append(parts, block)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func (r *adfRenderer) block(n *ADFNode, indent string) string {
+	switch n.Type {
+	case "paragraph":
+		return indentLines(r.inline(n.Content), indent)
+	case "heading":
+		level, _ := strconv.Atoi(n.attr("level"))
+		level = max(1, min(level, 6))
+		return indent + strings.Repeat("#", level) + " " + r.inline(n.Content)
+	case "bulletList":
+		return r.list(n, indent, false)
+	case "orderedList":
+		return r.list(n, indent, true)
+	case "codeBlock":
+		code := ""
+		for _, c := range n.Content {
+			code += c.Text
+		}
+		return indentLines(fmt.Sprintf("```%s\n%s\n```", n.attr("language"), code), indent)
+	case "blockquote", "panel":
+		return prefixLines(r.blocks(n.Content, ""), indent+"> ")
+	case "rule":
+		return indent + "---"
+	case "table":
+		return indentLines(r.table(n), indent)
+	case "mediaSingle", "mediaGroup":
+		return indent + "[attachment]"
+	default:
+		// unknown blocks: keep whatever text they carry
+		if len(n.Content) > 0 {
+			return r.blocks(n.Content, indent)
+		}
+		return indentLines(r.inline([]*ADFNode{n}), indent)
+	}
+}
+
+func (r *adfRenderer) list(n *ADFNode, indent string, ordered bool) string {
+	start := 1
+	if ordered {
+		if order, err := strconv.Atoi(n.attr("order")); err == nil {
+			start = order
+		}
+	}
+
+	items := []string{}
+	for i, item := range n.Content {
+		marker := "- "
+		if ordered {
+			marker = fmt.Sprintf("%d. ", start+i)
+		}
+		childIndent := indent + strings.Repeat(" ", len(marker))
+
+		lines := []string{}
+		for j, child := range item.Content {
+			block := r.block(child, childIndent)
+			if j == 0 {
+				block = indent + marker + strings.TrimPrefix(block, childIndent)
+			}
+			lines = append(lines, block)
+		}
+		items = append(items, strings.Join(lines, "\n"))
+	}
+	return strings.Join(items, "\n")
+}
+
+func (r *adfRenderer) table(n *ADFNode) string {
+	rows := []string{}
+	for i, row := range n.Content {
+		cells := []string{}
+		for _, cell := range row.Content {
+			text := strings.ReplaceAll(r.blocks(cell.Content, ""), "\n", " ")
+			cells = append(cells, strings.ReplaceAll(text, "|", "\\|"))
+		}
+		rows = append(rows, "| "+strings.Join(cells, " | ")+" |")
+		if i == 0 {
+			rows = append(rows, "|"+strings.Repeat(" --- |", len(cells)))
+		}
+	}
+	return strings.Join(rows, "\n")
+}
+
+func (r *adfRenderer) inline(nodes []*ADFNode) string {
+	var builder strings.Builder
+	for _, n := range nodes {
+		switch n.Type {
+		case "text":
+			builder.WriteString(applyMarks(n.Text, n.Marks))
+		case "hardBreak":
+			builder.WriteString("\n")
+		case "mention":
+			builder.WriteString(r.mention(n))
+		case "emoji":
+			if text := n.attr("text"); text != "" {
+				builder.WriteString(text)
+			} else {
+				builder.WriteString(n.attr("shortName"))
+			}
+		case "inlineCard", "blockCard":
+			builder.WriteString(fmt.Sprintf("<%s>", n.attr("url")))
+		case "status":
+			builder.WriteString(fmt.Sprintf("[%s]", n.attr("text")))
+		case "date":
+			ms, err := strconv.ParseInt(n.attr("timestamp"), 10, 64)
+			if err == nil {
+				builder.WriteString(time.UnixMilli(ms).UTC().Format("2006-01-02"))
+			}
+		default:
+			builder.WriteString(r.inline(n.Content))
+		}
+	}
+	return builder.String()
+}
+
+func (r *adfRenderer) mention(n *ADFNode) string {
+	name := strings.TrimPrefix(n.attr("text"), "@")
+	if name == "" && r.resolve != nil {
+		name = r.resolve(n.attr("id"))
+	}
+	if name == "" {
+		name = n.attr("id")
+	}
+	return "@" + name
+}
+
+func applyMarks(text string, marks []*ADFMark) string {
+	for _, mark := range marks {
+		switch mark.Type {
+		case "strong":
+			text = "**" + text + "**"
+		case "em":
+			text = "_" + text + "_"
+		case "strike":
+			text = "~~" + text + "~~"
+		case "code":
+			text = "`" + text + "`"
+		case "link":
+			if href, ok := mark.Attrs["href"].(string); ok {
+				text = fmt.Sprintf("[%s](%s)", text, href)
+			}
+		}
+	}
+	return text
+}
+
+func indentLines(text, indent string) string {
+	if indent == "" {
+		return text
+	}
+	return prefixLines(text, indent)
+}
+
+func prefixLines(text, prefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseADF decodes an ADF document. Plain JSON strings (v2 API, Jira
+// Server) are returned as a document with a single paragraph.
+func parseADF(raw json.RawMessage) (*ADFNode, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return &ADFNode{Type: "doc", Content: []*ADFNode{
+			{Type: "paragraph", Content: []*ADFNode{{Type: "text", Text: text}}},
+		}}, nil
+	}
+
+	doc := ADFNode{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse ADF document: %w", err)
+	}
+	return &doc, nil
+}
+
+// Issue is a Jira issue together with its rich text fields as ADF. The plain
+// text description and comment bodies of the v2 API are wrapped in a
+// one-paragraph document by parseADF, so the ADF fields are set either way and
+// the embedded jira.Issue holds neither.
+type Issue struct {
+	*jira.Issue
+	Description   *ADFNode
+	CommentBodies map[string]*ADFNode
+	Parent        *TicketRef
+}
+
+// decodeIssue decodes a v3 API issue. go-jira expects plain strings for the
+// description and comment bodies, so the ADF documents are taken out before
+// the rest of the issue is decoded.
+func decodeIssue(data []byte) (*Issue, error) {
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(data, &top); err != nil {
+		return nil, fmt.Errorf("failed to decode issue: %w", err)
+	}
+	fields := map[string]json.RawMessage{}
+	if rawFields, ok := top["fields"]; ok {
+		if err := json.Unmarshal(rawFields, &fields); err != nil {
+			return nil, fmt.Errorf("failed to decode issue fields: %w", err)
+		}
+	}
+
+	issue := Issue{CommentBodies: map[string]*ADFNode{}}
+
+	description, err := parseADF(fields["description"])
+	if err != nil {
+		return nil, err
+	}
+	issue.Description = description
+	delete(fields, "description")
+
+	parent, err := parseParent(fields["parent"])
+	if err != nil {
+		return nil, err
+	}
+	issue.Parent = parent
+
+	if rawComments, ok := fields["comment"]; ok {
+		var comment map[string]json.RawMessage
+		if err := json.Unmarshal(rawComments, &comment); err != nil {
+			return nil, fmt.Errorf("failed to decode comments: %w", err)
+		}
+		var comments []map[string]json.RawMessage
+		if err := json.Unmarshal(comment["comments"], &comments); err != nil && comment["comments"] != nil {
+			return nil, fmt.Errorf("failed to decode comments: %w", err)
+		}
+
+		for _, c := range comments {
+			var id string
+			json.Unmarshal(c["id"], &id)
+			body, err := parseADF(c["body"])
+			if err != nil {
+				return nil, err
+			}
+			issue.CommentBodies[id] = body
+			delete(c, "body")
+		}
+		comment["comments"], _ = json.Marshal(comments)
+		fields["comment"], _ = json.Marshal(comment)
+	}
+
+	top["fields"], _ = json.Marshal(fields)
+	stripped, _ := json.Marshal(top)
+
+	issue.Issue = new(jira.Issue)
+	if err := json.Unmarshal(stripped, issue.Issue); err != nil {
+		return nil, fmt.Errorf("failed to decode issue: %w", err)
+	}
+	return &issue, nil
+}
+
+// newUserResolver looks up display names of accounts, remembering every answer.
+func newUserResolver(client *Client) UserResolver {
+	names := map[string]string{}
+	return func(accountID string) string {
+		if name, ok := names[accountID]; ok {
+			return name
+		}
+		name := ""
+		if user, _, err := client.User.GetByAccountID(accountID); err == nil {
+			name = user.DisplayName
+		}
+		names[accountID] = name
+		return name
+	}
+}
@@ -0,0 +1,159 @@
+package jirautils
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Column is a column of the kanban board.
+type Column string
+
+const (
+	ColumnBacklog    Column = "Backlog"
+	ColumnToDo       Column = "To Do"
+	ColumnInProgress Column = "In Progress"
+	ColumnInReview   Column = "In Review"
+	ColumnBlocked    Column = "Blocked"
+	ColumnDone       Column = "Done"
+	ColumnCanceled   Column = "Canceled"
+)
+
+// columnOrder is the left-to-right order of the well-known columns. Custom
+// columns of a configured workflow follow them in alphabetical order.
+var columnOrder = []Column{
+	ColumnBacklog, ColumnToDo, ColumnInProgress, ColumnInReview, ColumnBlocked, ColumnDone, ColumnCanceled,
+}
+
+// Workflow maps the status names of a project to board columns.
+type Workflow map[string]Column
+
+// DefaultWorkflow is the workflow of the DX project.
+var DefaultWorkflow = Workflow{
+	"BACKLOG":                  ColumnBacklog,
+	"Selected for Development": ColumnToDo,
+	"In Progress":              ColumnInProgress,
+	"In Review":                ColumnInReview,
+	"Blocked":                  ColumnBlocked,
+	"Done":                     ColumnDone,
+	"Canceled":                 ColumnCanceled,
+}
+
+// NewWorkflow builds a workflow from the configured status-to-column mapping.
+func NewWorkflow(statuses map[string]string) Workflow {
+	workflow := Workflow{}
+	for status, column := range statuses {
+		workflow[status] = Column(column)
+	}
+	return workflow
+}
+
+// Column returns the column of a status. Status names are matched case
+// insensitively, as Jira does in JQL.
+func (w Workflow) Column(status string) (Column, bool) {
+	for name, column := range w {
+		if strings.EqualFold(name, status) {
+			return column, true
+		}
+	}
+	return "", false
+}
+
+// Board holds my tickets of a project, keyed by column.
+type Board struct {
+	Project string
+	Columns map[Column][]*Ticket
+}
+
+// OrderedColumns returns the columns of the board from left to right.
+func (b *Board) OrderedColumns() []Column {
+	columns := []Column{}
+	for _, column := range columnOrder {
+		if _, ok := b.Columns[column]; ok {
+			columns = append(columns, column)
+		}
+	}
+	custom := []Column{}
+	for column := range b.Columns {
+		if !slices.Contains(columnOrder, column) {
+			custom = append(custom, column)
+		}
+	}
+	sort.Slice(custom, func(i, j int) bool { return custom[i] < custom[j] })
+	return append(columns, custom...)
+}
+
+// newBoard sorts tickets into the columns of the workflow. Every column of the
+// workflow is present, even if empty; tickets in unmapped statuses are dropped.
+func newBoard(project string, workflow Workflow, tickets []*Ticket) *Board {
+	board := Board{Project: project, Columns: map[Column][]*Ticket{}}
+	for _, column := range workflow {
+		board.Columns[column] = []*Ticket{}
+	}
+	for _, ticket := range tickets {
+		column, ok := workflow.Column(ticket.Status)
+		if !ok {
+			slog.Debug("ticket status is not on the board", slog.String("ticket", ticket.Key), slog.String("status", ticket.Status))
+			continue
+		}
+		board.Columns[column] = append(board.Columns[column], ticket)
+	}
+	return &board
+}
+
+// GetBoard fetches my tickets of the project that are in a status of the
+// workflow and sorts them into the columns of the board.
+func GetBoard(client *Client, project string, workflow Workflow) (*Board, error) {
+	statuses := []string{}
+	for status := range workflow {
+		statuses = append(statuses, fmt.Sprintf("%q", status))
+	}
+	sort.Strings(statuses)
+
+	filter := Filter{
+		Name: fmt.Sprintf("Board %s", project),
+		Jql: fmt.Sprintf("project = %q AND type IN (standardIssueTypes(), subTaskIssueTypes()) AND assignee = currentUser() AND status IN (%s) ORDER BY created DESC",
+			project, strings.Join(statuses, ", ")),
+	}
+	tickets, err := GetTicketsByFilter(client, &filter)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("", slog.String("project", project), slog.Int("total tickets", len(tickets)))
+	return newBoard(project, workflow, tickets), nil
+}
+
+// Render writes the board as a Markdown table with one column per board
+// column and one ticket per cell.
+func (b *Board) Render(w io.Writer) {
+	columns := b.OrderedColumns()
+	if len(columns) == 0 {
+		fmt.Fprintf(w, "No columns configured for project %s.\n", b.Project)
+		return
+	}
+
+	header := []string{}
+	rows := 0
+	for _, column := range columns {
+		header = append(header, fmt.Sprintf("%s (%d)", column, len(b.Columns[column])))
+		rows = max(rows, len(b.Columns[column]))
+	}
+
+	fmt.Fprintf(w, "| %s |\n", strings.Join(header, " | "))
+	fmt.Fprintf(w, "|%s\n", strings.Repeat(" --- |", len(columns)))
+	for i := 0; i < rows; i++ {
+		cells := []string{}
+		for _, column := range columns {
+			cell := ""
+			if tickets := b.Columns[column]; i < len(tickets) {
+				cell = fmt.Sprintf("%s %s", tickets[i].Key, strings.ReplaceAll(tickets[i].Title, "|", "\\|"))
+			}
+			cells = append(cells, cell)
+		}
+		fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+	}
+}
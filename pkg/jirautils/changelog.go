@@ -0,0 +1,120 @@
+package jirautils
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// parseWindow turns the inclusive date range from..to into the half-open
+// interval [from 00:00, day after to 00:00) in the given location.
+func parseWindow(from, to string, loc *time.Location) (time.Time, time.Time, error) {
+	const layout = "2006-01-02"
+	start, err := time.ParseInLocation(layout, from, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid date string '%s': %w", from, err)
+	}
+	end, err := time.ParseInLocation(layout, to, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid date string '%s': %w", to, err)
+	}
+	return start, end.AddDate(0, 0, 1), nil
+}
+
+func inWindow(t, start, end time.Time) bool {
+	return !t.Before(start) && t.Before(end)
+}
+
+// newChangelogItem keeps the history entries of the issue that were authored
+// by the account within the window. It returns nil when there are none.
+func newChangelogItem(client *Client, issue *Issue, accountID string, start, end time.Time) (*ChangelogItem, error) {
+	if issue.Changelog == nil {
+		return nil, nil
+	}
+
+	changes := []*Change{}
+	for _, history := range issue.Changelog.Histories {
+		if UserID(&history.Author) != accountID {
+			continue
+		}
+		createdAt, err := parseJiraTime(history.Created)
+		if err != nil {
+			return nil, err
+		}
+		if !inWindow(createdAt, start, end) {
+			continue
+		}
+
+		for _, item := range history.Items {
+			changes = append(changes, &Change{
+				Field:     item.Field,
+				From:      item.FromString,
+				To:        item.ToString,
+				CreatedAt: createdAt,
+			})
+		}
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	ticket, err := client.newTicket(issue, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ChangelogItem{Ticket: ticket, Changes: changes}, nil
+}
+
+// GetUpdatedTickets returns the status transitions and field edits the account
+// made between from and to (both inclusive) on tickets it is involved in.
+func GetUpdatedTickets(client *Client, from, to, accountID string) ([]*ChangelogItem, error) {
+	start, end, err := parseWindow(from, to, client.location)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := Filter{
+		Name: "Updated today",
+		Jql: fmt.Sprintf("(assignee = currentUser() OR reporter = currentUser() OR watcher = currentUser()) AND updated >= \"%s\" AND updated < \"%s\" ORDER BY updated DESC",
+			from, end.Format("2006-01-02")),
+	}
+	opts := &jira.SearchOptions{Expand: "changelog", Fields: client.requestedFields()}
+	issues, err := GetIssues(client, &filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get issues for filter %s: %w", filter.Name, err)
+	}
+
+	changelogs := []*ChangelogItem{}
+	for _, issue := range issues {
+		changelog, err := newChangelogItem(client, issue, accountID, start, end)
+		if err != nil {
+			return nil, err
+		}
+		if changelog != nil {
+			changelogs = append(changelogs, changelog)
+		}
+	}
+	return changelogs, nil
+}
+
+// Describe renders every change as a short sentence, e.g.
+// "moved DX-408 from In Progress to In Review".
+func (cli *ChangelogItem) Describe() []string {
+	sentences := []string{}
+	for _, change := range cli.Changes {
+		var sentence string
+		switch {
+		case change.Field == "status":
+			sentence = fmt.Sprintf("moved %s from %s to %s", cli.Ticket.Key, change.From, change.To)
+		case change.From == "":
+			sentence = fmt.Sprintf("set %s of %s to %s", change.Field, cli.Ticket.Key, change.To)
+		case change.To == "":
+			sentence = fmt.Sprintf("cleared %s of %s (was %s)", change.Field, cli.Ticket.Key, change.From)
+		default:
+			sentence = fmt.Sprintf("changed %s of %s from %s to %s", change.Field, cli.Ticket.Key, change.From, change.To)
+		}
+		sentences = append(sentences, sentence)
+	}
+	return sentences
+}
@@ -0,0 +1,90 @@
+package jirautils
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// GetCurrentUser returns the authenticated user as reported by /myself.
+func GetCurrentUser(client *Client) (*jira.User, error) {
+	user, _, err := client.User.GetSelf()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the current Jira user: %w", err)
+	}
+	return user, nil
+}
+
+// GetCurrentAccountID returns the accountId (the username on Data Center) of
+// the authenticated user.
+func GetCurrentAccountID(client *Client) (string, error) {
+	user, err := GetCurrentUser(client)
+	if err != nil {
+		return "", err
+	}
+	return UserID(user), nil
+}
+
+// FilterComments keeps the comments created or edited between from and to
+// (both inclusive), read in the given location, and flags the ones written by
+// the given account.
+func (t *Ticket) FilterComments(from, to, accountID string, loc *time.Location) error {
+	start, end, err := parseWindow(from, to, loc)
+	if err != nil {
+		return err
+	}
+
+	comments := []*Comment{}
+	for _, c := range t.Comments {
+		if !inWindow(c.CreatedAt, start, end) && !inWindow(c.UpdatedAt, start, end) {
+			continue
+		}
+		c.Mine = c.AuthorAccountID == accountID
+		comments = append(comments, c)
+	}
+	t.Comments = comments
+	return nil
+}
+
+// HasMyComments tells whether any of the comments was written by the user.
+func (t *Ticket) HasMyComments() bool {
+	for _, c := range t.Comments {
+		if c.Mine {
+			return true
+		}
+	}
+	return false
+}
+
+// GetDiscussions returns the tickets the user commented on between from and
+// to (both inclusive), with the comments narrowed down to that window.
+// Commenting makes the user a watcher, so watched tickets updated in the
+// window are the candidates.
+func GetDiscussions(client *Client, from, to, accountID string) ([]*Ticket, error) {
+	_, end, err := parseWindow(from, to, client.location)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := Filter{
+		Name: "Discussions today",
+		Jql: fmt.Sprintf("watcher = currentUser() AND updated >= \"%s\" AND updated < \"%s\" ORDER BY updated DESC",
+			from, end.Format("2006-01-02")),
+	}
+	candidates, err := GetTicketsByFilter(client, &filter)
+	if err != nil {
+		return nil, err
+	}
+
+	tickets := []*Ticket{}
+	for _, ticket := range candidates {
+		if err := ticket.FilterComments(from, to, accountID, client.location); err != nil {
+			return nil, err
+		}
+		if ticket.HasMyComments() {
+			tickets = append(tickets, ticket)
+		}
+	}
+	return tickets, nil
+}
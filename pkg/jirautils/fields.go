@@ -0,0 +1,135 @@
+package jirautils
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// requestedFields returns the ticket fields followed by the sprint, story
+// points and custom fields of the instance.
+func (c *Client) requestedFields() []string {
+	fields := append([]string{}, ticketFields...)
+	for _, id := range []string{c.sprintField, c.storyPointsField, c.epicLinkField} {
+		if id != "" {
+			fields = append(fields, id)
+		}
+	}
+
+	ids := []string{}
+	for _, id := range c.customFields {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return append(fields, ids...)
+}
+
+// TicketLink is a link to another ticket, e.g. "blocks DX-76".
+type TicketLink struct {
+	Relation string
+	Key      string
+	Title    string
+	Status   string
+}
+
+func (l *TicketLink) String() string {
+	return fmt.Sprintf("%s %s", l.Relation, l.Key)
+}
+
+// newLinks describes the links from the point of view of the ticket: the
+// outward phrase ("blocks") for outward links, the inward phrase ("is blocked
+// by") for inward links.
+func newLinks(issueLinks []*jira.IssueLink) []*TicketLink {
+	links := []*TicketLink{}
+	for _, l := range issueLinks {
+		relation, other := l.Type.Outward, l.OutwardIssue
+		if other == nil {
+			relation, other = l.Type.Inward, l.InwardIssue
+		}
+		if other == nil {
+			continue
+		}
+
+		link := TicketLink{Relation: relation, Key: other.Key}
+		if other.Fields != nil {
+			link.Title = other.Fields.Summary
+			if other.Fields.Status != nil {
+				link.Status = other.Fields.Status.Name
+			}
+		}
+		links = append(links, &link)
+	}
+	return links
+}
+
+// Unblocked returns the tickets this ticket blocks.
+func (t *Ticket) Unblocked() []*TicketLink {
+	unblocked := []*TicketLink{}
+	for _, link := range t.Links {
+		if link.Relation == "blocks" {
+			unblocked = append(unblocked, link)
+		}
+	}
+	return unblocked
+}
+
+// customFieldValue renders the value of a custom field as text. Options,
+// users and versions are objects carrying their text in "value", "name" or
+// "displayName"; multi-value fields are arrays.
+func customFieldValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []any:
+		values := []string{}
+		for _, item := range v {
+			if s := customFieldValue(item); s != "" {
+				values = append(values, s)
+			}
+		}
+		return strings.Join(values, ", ")
+	case map[string]any:
+		for _, key := range []string{"value", "name", "displayName"} {
+			if s, ok := v[key].(string); ok {
+				return s
+			}
+		}
+		data, _ := json.Marshal(v)
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// setFields copies priority, type, labels, components, fix versions, links and
+// the custom fields of the issue to the ticket.
+func (t *Ticket) setFields(fields *jira.IssueFields, customFields map[string]string) {
+	if fields.Priority != nil {
+		t.Priority = fields.Priority.Name
+	}
+	t.Type = fields.Type.Name
+	t.Labels = fields.Labels
+	for _, component := range fields.Components {
+		t.Components = append(t.Components, component.Name)
+	}
+	for _, version := range fields.FixVersions {
+		t.FixVersions = append(t.FixVersions, version.Name)
+	}
+	if len(fields.IssueLinks) > 0 {
+		t.Links = newLinks(fields.IssueLinks)
+	}
+
+	for name, id := range customFields {
+		if value := customFieldValue(fields.Unknowns[id]); value != "" {
+			if t.CustomFields == nil {
+				t.CustomFields = map[string]string{}
+			}
+			t.CustomFields[name] = value
+		}
+	}
+}
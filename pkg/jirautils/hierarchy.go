@@ -0,0 +1,156 @@
+package jirautils
+
+import (
+	"encoding/json"
+	"fmt"
+	"perf/pkg/gh"
+	"slices"
+	"sort"
+)
+
+const epicType = "Epic"
+
+// TicketRef is a short reference to a parent or epic ticket.
+type TicketRef struct {
+	Key   string
+	Title string
+	Type  string
+}
+
+// IsEpic tells whether the referenced ticket is an epic, as parent or as
+// target of an "Epic Link" field.
+func (r *TicketRef) IsEpic() bool {
+	return r != nil && r.Type == epicType
+}
+
+// parseParent reads the "parent" field, which Jira returns with the summary
+// and issue type of the parent ticket.
+func parseParent(raw json.RawMessage) (*TicketRef, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var parent struct {
+		Key    string `json:"key"`
+		Fields struct {
+			Summary   string `json:"summary"`
+			IssueType struct {
+				Name string `json:"name"`
+			} `json:"issuetype"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal(raw, &parent); err != nil {
+		return nil, fmt.Errorf("failed to decode parent: %w", err)
+	}
+	return &TicketRef{Key: parent.Key, Title: parent.Fields.Summary, Type: parent.Fields.IssueType.Name}, nil
+}
+
+// ResolveEpics sets the epic of every ticket. A ticket whose parent is an epic
+// belongs to that epic; a sub-task belongs to the epic of its parent, which is
+// fetched for that from the instance hosting it. The titles of epics known
+// from an "Epic Link" field only are fetched as well.
+func ResolveEpics(router *Router, tickets []*Ticket) error {
+	parentKeys := []string{}
+	for _, ticket := range tickets {
+		switch {
+		case ticket.Epic != nil || ticket.Parent == nil:
+		case ticket.Parent.IsEpic():
+			ticket.Epic = ticket.Parent
+		default:
+			parentKeys = append(parentKeys, ticket.Parent.Key)
+		}
+	}
+
+	if len(parentKeys) > 0 {
+		parents, err := router.GetTicketsByKeys(parentKeys)
+		if err != nil {
+			return err
+		}
+		for _, ticket := range tickets {
+			if ticket.Epic != nil || ticket.Parent == nil {
+				continue
+			}
+			parent, ok := parents[ticket.Parent.Key]
+			switch {
+			case !ok:
+			case parent.Epic != nil:
+				ticket.Epic = parent.Epic
+			case parent.Parent.IsEpic():
+				ticket.Epic = parent.Parent
+			}
+		}
+	}
+
+	untitled := []string{}
+	for _, ticket := range tickets {
+		if ticket.Epic != nil && ticket.Epic.Title == "" && !slices.Contains(untitled, ticket.Epic.Key) {
+			untitled = append(untitled, ticket.Epic.Key)
+		}
+	}
+	if len(untitled) == 0 {
+		return nil
+	}
+	epics, err := router.GetTicketsByKeys(untitled)
+	if err != nil {
+		return err
+	}
+	for _, ticket := range tickets {
+		if ticket.Epic == nil || ticket.Epic.Title != "" {
+			continue
+		}
+		if epic, ok := epics[ticket.Epic.Key]; ok {
+			ticket.Epic.Title = epic.Title
+		}
+	}
+	return nil
+}
+
+// TicketGroup is a set of tickets reported as one bullet: all tickets of an
+// epic, the sub-tasks of a story, or a single standalone ticket.
+type TicketGroup struct {
+	Key     string
+	Title   string
+	Epic    bool
+	Tickets []*Ticket
+}
+
+// PullRequests returns the pull requests of all tickets of the group.
+func (g *TicketGroup) PullRequests() []*gh.PullRequest {
+	prs := []*gh.PullRequest{}
+	for _, ticket := range g.Tickets {
+		prs = append(prs, ticket.PullRequests...)
+	}
+	return prs
+}
+
+// GroupTickets rolls tickets up into their epic, or into their parent when
+// they have no epic. The groups and their tickets are sorted by key.
+func GroupTickets(tickets []*Ticket) []*TicketGroup {
+	groups := map[string]*TicketGroup{}
+	for _, ticket := range tickets {
+		ref := &TicketRef{Key: ticket.Key, Title: ticket.Title}
+		switch {
+		case ticket.Epic != nil:
+			ref = ticket.Epic
+		case ticket.Parent != nil:
+			ref = ticket.Parent
+		}
+
+		group, ok := groups[ref.Key]
+		if !ok {
+			group = &TicketGroup{Key: ref.Key, Title: ref.Title}
+			groups[ref.Key] = group
+		}
+		// members may reference the epic as epic or as parent
+		group.Epic = group.Epic || ref.IsEpic()
+		group.Tickets = append(group.Tickets, ticket)
+	}
+
+	sorted := []*TicketGroup{}
+	for _, group := range groups {
+		sort.Slice(group.Tickets, func(i, j int) bool { return group.Tickets[i].Key < group.Tickets[j].Key })
+		sorted = append(sorted, group)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Key < sorted[j].Key })
+	return sorted
+}
@@ -0,0 +1,232 @@
+package jirautils
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+// Instance is a Jira site and the projects it hosts.
+type Instance struct {
+	URL string
+	// DataCenter instances authenticate with a personal access token as bearer
+	// token and only offer the v2 REST API.
+	DataCenter bool
+	Username   string
+	Token      string
+	// CustomFields maps the names of custom fields added to every ticket to
+	// their field IDs, e.g. "Team" to "customfield_10001".
+	CustomFields map[string]string
+	// SprintField and StoryPointsField are the IDs of the custom fields
+	// holding the sprints and the story points of a ticket.
+	SprintField      string
+	StoryPointsField string
+	// EpicLinkField is the ID of the "Epic Link" field of Data Center
+	// instances, where stories do not have their epic as parent.
+	EpicLinkField string
+	// Location is the time zone the dates of a report are read in. It
+	// defaults to UTC.
+	Location *time.Location
+}
+
+// Client is the client of a Jira instance together with the settings and the
+// issues fetched from that instance during a run.
+type Client struct {
+	*jira.Client
+	dataCenter       bool
+	customFields     map[string]string
+	sprintField      string
+	storyPointsField string
+	epicLinkField    string
+	location         *time.Location
+
+	mu     sync.Mutex
+	issues map[string]*Issue
+}
+
+// NewClient wraps a go-jira client of the instance.
+func NewClient(client *jira.Client, instance *Instance) *Client {
+	customFields := map[string]string{}
+	for name, id := range instance.CustomFields {
+		customFields[name] = id
+	}
+	location := instance.Location
+	if location == nil {
+		location = time.UTC
+	}
+	return &Client{
+		Client:           client,
+		dataCenter:       instance.DataCenter,
+		customFields:     customFields,
+		sprintField:      instance.SprintField,
+		storyPointsField: instance.StoryPointsField,
+		epicLinkField:    instance.EpicLinkField,
+		location:         location,
+		issues:           map[string]*Issue{},
+	}
+}
+
+// Location returns the time zone the dates of a report are read in.
+func (c *Client) Location() *time.Location {
+	return c.location
+}
+
+func InitJiraClient(instance *Instance) (*Client, error) {
+	if instance.Token == "" {
+		return nil, fmt.Errorf("missing API token for Jira at %s", instance.URL)
+	}
+
+	if instance.DataCenter {
+		tp := jira.PATAuthTransport{Token: instance.Token}
+		client, err := jira.NewClient(tp.Client(), instance.URL)
+		if err != nil {
+			return nil, err
+		}
+		return NewClient(client, instance), nil
+	}
+
+	if instance.Username == "" {
+		return nil, fmt.Errorf("missing username for Jira at %s", instance.URL)
+	}
+	tp := jira.BasicAuthTransport{
+		Username: instance.Username,
+		Password: instance.Token,
+	}
+	client, err := jira.NewClient(tp.Client(), instance.URL)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(client, instance), nil
+}
+
+// apiPath returns the REST path of the newest API the instance offers: v3
+// (ADF rich text) on Cloud, v2 (plain text) on Data Center.
+func apiPath(client *Client, path string) string {
+	if client.dataCenter {
+		return "rest/api/2/" + path
+	}
+	return "rest/api/3/" + path
+}
+
+// UserID identifies a user: the accountId on Cloud, the username on Data
+// Center, which has no accountIds. Both work in JQL.
+func UserID(user *jira.User) string {
+	if user.AccountID != "" {
+		return user.AccountID
+	}
+	return user.Name
+}
+
+// Router picks the Jira instance of a ticket by the project key of its
+// ticket key.
+type Router struct {
+	instances []*routedInstance
+}
+
+type routedInstance struct {
+	client    *Client
+	projects  []string
+	accountID string
+}
+
+func NewRouter() *Router {
+	return &Router{}
+}
+
+// Add registers the client of an instance hosting the given projects. An
+// instance without projects receives the tickets of all projects not claimed
+// by another instance.
+func (r *Router) Add(client *Client, projects []string) {
+	r.instances = append(r.instances, &routedInstance{client: client, projects: projects})
+}
+
+// Default returns the client of the first instance without projects, or of
+// the first instance if every instance lists its projects.
+func (r *Router) Default() *Client {
+	return r.fallback().client
+}
+
+func (r *Router) fallback() *routedInstance {
+	for _, instance := range r.instances {
+		if len(instance.projects) == 0 {
+			return instance
+		}
+	}
+	return r.instances[0]
+}
+
+func (r *Router) instance(key string) *routedInstance {
+	project, _, _ := strings.Cut(key, "-")
+	return r.projectInstance(project)
+}
+
+func (r *Router) projectInstance(project string) *routedInstance {
+	for _, instance := range r.instances {
+		for _, p := range instance.projects {
+			if strings.EqualFold(p, project) {
+				return instance
+			}
+		}
+	}
+	return r.fallback()
+}
+
+// Clients returns the clients of all instances.
+func (r *Router) Clients() []*Client {
+	clients := []*Client{}
+	for _, instance := range r.instances {
+		clients = append(clients, instance.client)
+	}
+	return clients
+}
+
+// Client returns the client of the instance hosting the ticket.
+func (r *Router) Client(key string) *Client {
+	return r.instance(key).client
+}
+
+// ProjectClient returns the client of the instance hosting the project.
+func (r *Router) ProjectClient(project string) *Client {
+	return r.projectInstance(project).client
+}
+
+// CurrentAccountID returns my identity on the instance hosting the ticket.
+func (r *Router) CurrentAccountID(key string) (string, error) {
+	instance := r.instance(key)
+	if instance.accountID == "" {
+		accountID, err := GetCurrentAccountID(instance.client)
+		if err != nil {
+			return "", err
+		}
+		instance.accountID = accountID
+	}
+	return instance.accountID, nil
+}
+
+// GetTicketsByKeys fetches the tickets from the instances hosting them.
+func (r *Router) GetTicketsByKeys(keys []string) (map[string]*Ticket, error) {
+	keysByInstance := map[*routedInstance][]string{}
+	order := []*routedInstance{}
+	for _, key := range keys {
+		instance := r.instance(key)
+		if _, ok := keysByInstance[instance]; !ok {
+			order = append(order, instance)
+		}
+		keysByInstance[instance] = append(keysByInstance[instance], key)
+	}
+
+	tickets := map[string]*Ticket{}
+	for _, instance := range order {
+		found, err := GetTicketsByKeys(instance.client, keysByInstance[instance])
+		if err != nil {
+			return nil, err
+		}
+		for key, ticket := range found {
+			tickets[key] = ticket
+		}
+	}
+	return tickets, nil
+}
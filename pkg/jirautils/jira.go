@@ -1,13 +1,11 @@
 package jirautils
 
 import (
-	"bytes"
 	"encoding/json"
 	"log/slog"
-	"net/http"
 	"net/url"
-	"os"
 	"perf/pkg/gh"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,23 +14,14 @@ import (
 	"github.com/andygrunwald/go-jira"
 )
 
-var (
-	FilterAlreadyExistsError FilterAlreadyExists
-)
-
+// Filter selects tickets either by JQL or, when ID is set, by the JQL of an
+// existing saved filter on the Jira server.
 type Filter struct {
+	ID   string
 	Name string
 	Jql  string
 }
 
-type Board struct {
-	ToDo       []jira.Issue
-	InProgress []jira.Issue
-	InReview   []jira.Issue
-	Blocked    []jira.Issue
-	Done       []jira.Issue
-}
-
 type Ticket struct {
 	Key          string
 	Created      time.Time
@@ -43,15 +32,30 @@ type Ticket struct {
 	Title        string
 	Body         string
 	Status       string
+	URL          string            `json:",omitempty"`
+	Priority     string            `json:",omitempty"`
+	Type         string            `json:",omitempty"`
+	Labels       []string          `json:",omitempty"`
+	Components   []string          `json:",omitempty"`
+	FixVersions  []string          `json:",omitempty"`
+	Links        []*TicketLink     `json:",omitempty"`
+	CustomFields map[string]string `json:",omitempty"`
+	Parent       *TicketRef        `json:",omitempty"`
+	Epic         *TicketRef        `json:",omitempty"`
+	Sprint       *Sprint           `json:",omitempty"`
+	StoryPoints  float64           `json:",omitempty"`
 	PullRequests []*gh.PullRequest
 	Comments     []*Comment
+	Worklogs     []*Worklog
 }
 
 type Comment struct {
-	Author    string
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	Body      string
+	Author          string
+	AuthorAccountID string `json:"-"`
+	Mine            bool
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	Body            string
 }
 
 type ChangelogItem struct {
@@ -60,9 +64,10 @@ type ChangelogItem struct {
 }
 
 type Change struct {
-	Field string
-	From  string
-	To    string
+	Field     string
+	From      string
+	To        string
+	CreatedAt time.Time
 }
 
 func (c *Change) String() string {
@@ -116,58 +121,56 @@ func (c *Comment) String() string {
 	return string(data)
 }
 
-type FilterAlreadyExists struct {
-	name string
-}
-
-func NewFilterAlreadyExistsError(name string) FilterAlreadyExists {
-	return FilterAlreadyExists{name: name}
-}
-
-func (e FilterAlreadyExists) Error() string {
-	return fmt.Sprintf("filter with name '%s' already exists", e.name)
-}
-
-func GetIssues(client *jira.Client, filter *jira.Filter, opts *jira.SearchOptions) ([]jira.Issue, error) {
-	issues, _, err := client.Issue.Search(filter.Jql, opts)
+func GetIssues(client *Client, filter *Filter, opts *jira.SearchOptions) ([]*Issue, error) {
+	issues, err := SearchIssues(client, filter.Jql, opts)
 	if err != nil {
 		return nil, err
 	}
-	if issues != nil {
-		fmt.Printf("found %d issues for filter '%s'\n", len(issues), filter.Name)
-	}
-	for _, is := range issues {
-		fmt.Printf("ID: %s\n", is.Key)
-	}
+	slog.Debug("found issues", slog.String("filter", filter.Name), slog.Int("count", len(issues)))
 	return issues, nil
 }
 
-func GetIssue(client *jira.Client, key string) (*jira.Issue, error) {
-	// TODO: specify options for specific fields, by default it pulls all of them
-	opts := &jira.GetQueryOptions{
-		Fields: "assignee,creator,reporter,summary,description,comment,created,updated,status",
-	}
-	issue, _, err := client.Issue.Get(key, opts)
+var ticketFields = []string{
+	"assignee", "creator", "reporter", "summary", "description", "comment", "created", "updated", "status", "parent",
+	"priority", "issuetype", "labels", "components", "issuelinks", "fixVersions",
+}
+
+// GetIssue fetches an issue from the v3 API, which returns the description
+// and comments as ADF, or from the v2 API on Data Center.
+func GetIssue(client *Client, key string) (*Issue, error) {
+	values := url.Values{}
+	values.Set("fields", strings.Join(client.requestedFields(), ","))
+	req, err := client.NewRequest("GET", apiPath(client, fmt.Sprintf("issue/%s?%s", key, values.Encode())), nil)
 	if err != nil {
+		return nil, fmt.Errorf("failed to prepare a request for ticket with key %s: %w", key, err)
+	}
+
+	var raw json.RawMessage
+	if _, err := client.Do(req, &raw); err != nil {
 		return nil, fmt.Errorf("failed to fetch ticket with key %s: %w", key, err)
 	}
-	return issue, nil
+	return decodeIssue(raw)
 }
 
-func GetTicketByKey(client *jira.Client, key string) (*Ticket, error) {
-	jIssue, err := GetIssue(client, key)
-	if err != nil {
-		return nil, err
+func GetTicketByKey(client *Client, key string) (*Ticket, error) {
+	issue, ok := client.cachedIssue(key)
+	if !ok {
+		var err error
+		issue, err = GetIssue(client, key)
+		if err != nil {
+			return nil, err
+		}
+		client.cacheIssue(issue)
 	}
 
-	ticket, err := newTicket(jIssue)
+	ticket, err := client.newTicket(issue, newUserResolver(client))
 	if err != nil {
 		return nil, err
 	}
 	return ticket, nil
 }
 
-func GetProjectId(jiraClient *jira.Client, projectName string) string {
+func GetProjectId(jiraClient *Client, projectName string) string {
 	if jiraClient == nil {
 		return ""
 	}
@@ -185,183 +188,54 @@ func GetProjectId(jiraClient *jira.Client, projectName string) string {
 	return projectId
 }
 
-func InitJiraClient() (*jira.Client, error) {
-	domain := "https://goflink.atlassian.net"
-	token, ok := os.LookupEnv("JIRA_API_TOKEN")
-	if !ok {
-		return nil, fmt.Errorf("missing JIRA_API_TOKEN")
-	}
-
-	username, ok := os.LookupEnv("JIRA_USERNAME")
-	if !ok {
-		return nil, fmt.Errorf("missing JIRA_USERNAME")
-	}
-	tp := jira.BasicAuthTransport{
-		Username: username,
-		Password: token,
-	}
-	client, err := jira.NewClient(tp.Client(), domain)
-	return client, err
-}
-
-func UpdateFilter(client *jira.Client, filter *jira.Filter, Jql string) error {
-	filterID := filter.ID
-
-	url := "/rest/api/3/filter/" + filterID
-	body := map[string]string{
-		"jql":  Jql,
-		"name": filter.Name,
-	}
-	data, _ := json.Marshal(body)
-
-	req, err := client.NewRequest("PUT", url, body)
-	if err != nil {
-		return fmt.Errorf("failed to prepare a PUT request to %s to update filter with ID %s: %w", url, filterID, err)
-	}
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Content-Type", "application/json")
-
-	fmt.Printf("payload for PUT: %s\n", data)
-	fmt.Printf("Host for PUT: %s\n", req.URL.Host)
-	fmt.Printf("path for PUT: %s\n", req.URL.Path)
-	fmt.Printf("headers for PUT: %v\n", req.Header)
-
-	resp, err := client.Do(req, nil)
+// GetSavedFilter reads a saved filter by its ID without modifying it.
+func GetSavedFilter(client *Client, filterID string) (*Filter, error) {
+	id, err := strconv.Atoi(filterID)
 	if err != nil {
-		return fmt.Errorf("an API error: failed to process PUT request to %s to delete filter with ID %s: %w",
-			url, filterID, err)
-	}
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("[%d] failed to update filter with ID %s", resp.StatusCode, filterID)
+		return nil, fmt.Errorf("invalid filter ID '%s': %w", filterID, err)
 	}
-	return nil
-}
-
-func GetFilter(client *jira.Client, filterName string) (*jira.Filter, error) {
-	var result struct {
-		Total   int            `json:"total"`
-		Filters []*jira.Filter `json:"values"`
-	}
-
-	values := url.Values{}
-	// values.Set("accountId", "712020")
-	values.Set("filterName", filterName)
-	values.Set("expand", "jql,owner") // Ensure expanded fields
-	params := values.Encode()
 
-	req, _ := client.NewRequest("GET", "/rest/api/3/filter/search?"+params, nil)
-
-	fmt.Println(req.URL.String())
-	_, err := client.Do(req, &result)
+	jFilter, _, err := client.Filter.Get(id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check if filter with name '%s' already exists: %s",
-			filterName, err.Error())
-	}
-
-	fmt.Printf("got filters\n")
-	for _, filter := range result.Filters {
-		// fmt.Printf("filter: %s\n", filter.Name)
-		if filter.Name == filterName {
-			fmt.Printf("this is our filter\n")
-			fmt.Printf("Jql: %s\n", filter.Jql)
-			return filter, nil
-		}
+		return nil, fmt.Errorf("failed to get filter with ID %s: %w", filterID, err)
 	}
-	return nil, nil
+	return &Filter{ID: filterID, Name: jFilter.Name, Jql: jFilter.Jql}, nil
 }
 
-func CreateFilter(client *jira.Client, filterName, jql string) (*jira.Filter, error) {
-	filter, err := GetFilter(client, filterName)
-	if err != nil {
-		return nil, err
-	}
-
-	if filter != nil {
-		err := UpdateFilter(client, filter, jql)
+// GetTicketsByFilter searches tickets with the JQL of the filter. Saved
+// filters are only used when the filter has an ID; nothing is created or
+// updated on the Jira server.
+func GetTicketsByFilter(client *Client, filter *Filter) ([]*Ticket, error) {
+	if filter.ID != "" {
+		saved, err := GetSavedFilter(client, filter.ID)
 		if err != nil {
 			return nil, err
 		}
-		fmt.Println("updated the filter")
-		return filter, nil
-	}
-
-	payload := map[string]string{
-		"jql":  jql,
-		"name": filterName,
+		filter = saved
 	}
-	req, _ := client.NewRequest("POST", "/rest/api/3/filter", payload)
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Content-Type", "application/json")
 
-	filter = new(jira.Filter)
-	// fmt.Printf("req: %v\n", req)
-	buf := new(bytes.Buffer)
-	json.NewEncoder(buf).Encode(payload)
-	fmt.Println("JSON payload:", buf.String())
-
-	_, err = client.Do(req, filter)
+	issues, err := GetIssues(client, filter, nil)
 	if err != nil {
-		return nil, err
-	}
-	return filter, nil
-}
-
-func GetTicketsByFilter(client *jira.Client, filter *Filter) ([]*Ticket, error) {
-	jFilter, err := CreateFilter(client, filter.Name, filter.Jql)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create a filter for %s tickets: %w", filter.Name, err)
-	}
-	issues, err := GetIssues(client, jFilter, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get issues for filter %s: %s", jFilter.Name, err.Error())
+		return nil, fmt.Errorf("failed to get issues for filter %s: %s", filter.Name, err.Error())
 	}
 
+	resolve := newUserResolver(client)
 	allTickets := []*Ticket{}
 	for _, issue := range issues {
-
-		ticket, err := GetTicketByKey(client, issue.Key)
+		client.cacheIssue(issue)
+		ticket, err := client.newTicket(issue, resolve)
 		if err != nil {
 			return nil, err
 		}
-
 		allTickets = append(allTickets, ticket)
 	}
 	return allTickets, nil
 }
 
-func GetBoard(client *jira.Client) ([]*Ticket, error) {
-	allTickets := []*Ticket{}
-
-	filterNames := []string{
-		"Selected for Development",
-		"In Progress",
-		"In Review",
-		"Blocked",
-		"Canceled",
-		"Done",
-		"BACKLOG",
-	}
-
-	for _, name := range filterNames {
-		f := Filter{
-			Name: name,
-			Jql:  fmt.Sprintf("project = DX AND type IN (standardIssueTypes(), subTaskIssueTypes()) AND assignee = currentUser() AND status = \"%s\" ORDER BY created DESC", name),
-		}
-
-		tickets, err := GetTicketsByFilter(client, &f)
-		if err != nil {
-			return nil, err
-		}
-
-		allTickets = append(allTickets, tickets...)
-	}
-
-	slog.Info("", slog.Int("total tickets", len(allTickets)))
-
-	return allTickets, nil
-}
-
-func newTicket(jIssue *jira.Issue) (*Ticket, error) {
+// newTicket converts an issue of the instance. Rich text in ADF is rendered as
+// Markdown, mentions are resolved to display names with resolve.
+func (c *Client) newTicket(issue *Issue, resolve UserResolver) (*Ticket, error) {
+	jIssue := issue.Issue
 	ticket := Ticket{
 		Key:      jIssue.Key,
 		Created:  time.Time(jIssue.Fields.Created),
@@ -372,6 +246,21 @@ func newTicket(jIssue *jira.Issue) (*Ticket, error) {
 		Status:   jIssue.Fields.Status.Name,
 		Body:     jIssue.Fields.Description,
 	}
+	if issue.Description != nil {
+		ticket.Body = ADFToMarkdown(issue.Description, resolve)
+	}
+	ticket.Parent = issue.Parent
+	ticket.URL = browseURL(jIssue)
+	ticket.setFields(jIssue.Fields, c.customFields)
+	if c.sprintField != "" {
+		ticket.Sprint = sprintFromField(jIssue.Fields.Unknowns[c.sprintField])
+	}
+	if points, ok := jIssue.Fields.Unknowns[c.storyPointsField].(float64); ok && c.storyPointsField != "" {
+		ticket.StoryPoints = points
+	}
+	if key, ok := jIssue.Fields.Unknowns[c.epicLinkField].(string); ok && key != "" && c.epicLinkField != "" {
+		ticket.Epic = &TicketRef{Key: key, Type: epicType}
+	}
 	if jIssue.Fields.Assignee != nil {
 		ticket.Assignee = jIssue.Fields.Assignee.DisplayName
 	}
@@ -380,7 +269,7 @@ func newTicket(jIssue *jira.Issue) (*Ticket, error) {
 		if len(jIssue.Fields.Comments.Comments) > 0 {
 			comments := []*Comment{}
 			for _, c := range jIssue.Fields.Comments.Comments {
-				comment, err := newComment(c)
+				comment, err := newComment(c, issue.CommentBodies[c.ID], resolve)
 				if err != nil {
 					return nil, err
 				}
@@ -392,7 +281,9 @@ func newTicket(jIssue *jira.Issue) (*Ticket, error) {
 	return &ticket, nil
 }
 
-func newComment(c *jira.Comment) (*Comment, error) {
+// newComment converts a comment. body is its ADF document if the comment was
+// read from the v3 API; otherwise the plain text body is kept.
+func newComment(c *jira.Comment, body *ADFNode, resolve UserResolver) (*Comment, error) {
 	createdAt, err := parseJiraTime(c.Created)
 	if err != nil {
 		return nil, err
@@ -403,14 +294,36 @@ func newComment(c *jira.Comment) (*Comment, error) {
 	}
 
 	comment := Comment{
-		Author:    c.Author.Name,
-		CreatedAt: createdAt,
-		UpdatedAt: updatedAt,
-		Body:      c.Body,
+		Author:          userName(&c.Author),
+		AuthorAccountID: UserID(&c.Author),
+		CreatedAt:       createdAt,
+		UpdatedAt:       updatedAt,
+		Body:            c.Body,
+	}
+	if body != nil {
+		comment.Body = ADFToMarkdown(body, resolve)
 	}
 	return &comment, nil
 }
 
+// browseURL derives the web link of an issue from its API link.
+func browseURL(issue *jira.Issue) string {
+	base, _, found := strings.Cut(issue.Self, "/rest/api/")
+	if !found || issue.Key == "" {
+		return ""
+	}
+	return base + "/browse/" + issue.Key
+}
+
+// userName returns the display name of a user. Jira Cloud leaves the name
+// empty, Jira Server may leave the display name empty.
+func userName(user *jira.User) string {
+	if user.DisplayName != "" {
+		return user.DisplayName
+	}
+	return user.Name
+}
+
 func parseJiraTime(t string) (time.Time, error) {
 	const jiraLayout = "2006-01-02T15:04:05.000-0700"
 	parsed, err := time.Parse(jiraLayout, t)
@@ -420,21 +333,29 @@ func parseJiraTime(t string) (time.Time, error) {
 	return parsed, nil
 }
 
-func AggPullRequestsByTicket(client *jira.Client, prs []*gh.PullRequest) (map[string]*Ticket, error) {
-	relevantTickets := map[string]*Ticket{}
+// AggPullRequestsByTicket fetches the tickets of the pull requests, each from
+// the instance hosting its project, and attaches the pull requests to them.
+// Pull requests of tickets that cannot be found are logged and skipped.
+func AggPullRequestsByTicket(router *Router, prs []*gh.PullRequest) (map[string]*Ticket, error) {
+	keys := []string{}
+	for _, pr := range prs {
+		keys = append(keys, pr.Ticket)
+	}
+
+	relevantTickets, err := router.GetTicketsByKeys(keys)
+	if err != nil {
+		return nil, err
+	}
 
 	for _, pr := range prs {
-		if ticket, exists := relevantTickets[pr.Ticket]; exists {
-			ticket.AddPullRequest(pr)
+		ticket, exists := relevantTickets[pr.Ticket]
+		if !exists {
+			// deleted, mistyped or inaccessible tickets leave the PR out of the tickets
+			slog.Warn("skipping pull request of an unknown ticket",
+				slog.String("pr", gh.CreateMapKey(pr.Owner, pr.Repo, pr.Number)), slog.String("ticket", pr.Ticket))
 			continue
 		}
-
-		ticket, err := GetTicketByKey(client, pr.Ticket)
-		if err != nil {
-			return nil, err
-		}
 		ticket.AddPullRequest(pr)
-		relevantTickets[pr.Ticket] = ticket
 	}
 	return relevantTickets, nil
 }
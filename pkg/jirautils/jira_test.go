@@ -1,10 +1,20 @@
 package jirautils
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"perf/pkg/gh"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/andygrunwald/go-jira"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParseJiraTime(t *testing.T) {
@@ -32,9 +42,731 @@ func TestParseJiraTime(t *testing.T) {
 	}
 }
 
+// testLocation is the time zone of the test instances, pinned so that the
+// date windows do not depend on the zone of the machine running the tests.
+var testLocation = time.FixedZone("CEST", 2*60*60)
+
+// newTestServer starts a stand-in Jira server and returns a client for it.
+// Every request is recorded as "METHOD path".
+func newTestServer(t *testing.T, handler http.HandlerFunc) (*Client, *[]string) {
+	return newTestInstance(t, &Instance{Location: testLocation}, handler)
+}
+
+// newTestInstance is newTestServer for an instance with custom settings.
+func newTestInstance(t *testing.T, instance *Instance, handler http.HandlerFunc) (*Client, *[]string) {
+	requests := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := jira.NewClient(server.Client(), server.URL)
+	require.NoError(t, err)
+	return NewClient(client, instance), &requests
+}
+
+const testIssue = `{
+	"key": "DX-408",
+	"fields": {
+		"summary": "Setup easy and composable Developer Environments",
+		"created": "2025-06-16T10:00:00.000+0200",
+		"updated": "2025-06-16T12:00:00.000+0200",
+		"creator": {"displayName": "Kristina Pianykh"},
+		"reporter": {"displayName": "Kristina Pianykh"},
+		"status": {"name": "In Progress"}
+	}
+}`
+
+func TestGetTicketsByFilter(t *testing.T) {
+	var jql string
+	client, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rest/api/3/search":
+			jql = r.URL.Query().Get("jql")
+			fmt.Fprintf(w, `{"issues": [%s], "total": 1}`, testIssue)
+		case "/rest/api/2/filter/42":
+			fmt.Fprint(w, `{"id": "42", "name": "Mine", "jql": "assignee = currentUser()"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	tickets, err := GetTicketsByFilter(client, &Filter{Name: "Created today", Jql: "project = DX"})
+	require.NoError(t, err)
+	assert.Len(t, tickets, 1)
+	assert.Equal(t, "DX-408", tickets[0].Key)
+	assert.Equal(t, "project = DX", jql)
+
+	tickets, err = GetTicketsByFilter(client, &Filter{ID: "42"})
+	require.NoError(t, err)
+	assert.Len(t, tickets, 1)
+	assert.Equal(t, "assignee = currentUser()", jql)
+
+	for _, request := range *requests {
+		assert.True(t, strings.HasPrefix(request, "GET "), "unexpected request %s", request)
+	}
+}
+
+func TestGetUpdatedTickets(t *testing.T) {
+	client, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "changelog", r.URL.Query().Get("expand"))
+		fmt.Fprint(w, `{"issues": [{
+			"key": "DX-408",
+			"fields": {
+				"summary": "Setup easy and composable Developer Environments",
+				"created": "2025-06-10T10:00:00.000+0200",
+				"updated": "2025-06-16T12:00:00.000+0200",
+				"creator": {"displayName": "Kristina Pianykh"},
+				"reporter": {"displayName": "Kristina Pianykh"},
+				"status": {"name": "In Review"}
+			},
+			"changelog": {"histories": [
+				{"author": {"accountId": "me", "displayName": "Kristina Pianykh"}, "created": "2025-06-16T00:30:00.000+0200",
+				 "items": [{"field": "status", "fromString": "To Do", "toString": "In Progress"}]},
+				{"author": {"accountId": "me", "displayName": "Kristina Pianykh"}, "created": "2025-06-16T11:00:00.000+0200",
+				 "items": [{"field": "status", "fromString": "In Progress", "toString": "In Review"}]},
+				{"author": {"accountId": "someone", "displayName": "Kristina Pianykh"}, "created": "2025-06-16T11:30:00.000+0200",
+				 "items": [{"field": "assignee", "fromString": "", "toString": "Someone Else"}]},
+				{"author": {"accountId": "me", "displayName": "Kristina Pianykh"}, "created": "2025-06-12T09:00:00.000+0200",
+				 "items": [{"field": "Story Points", "fromString": "3", "toString": "5"}]}
+			]}
+		}], "total": 1}`)
+	})
+
+	changelogs, err := GetUpdatedTickets(client, "2025-06-16", "2025-06-16", "me")
+	require.NoError(t, err)
+	require.Len(t, changelogs, 1)
+	assert.Equal(t, "DX-408", changelogs[0].Ticket.Key)
+	assert.Equal(t, []string{
+		"moved DX-408 from To Do to In Progress",
+		"moved DX-408 from In Progress to In Review",
+	}, changelogs[0].Describe())
+}
+
+func TestDescribeChanges(t *testing.T) {
+	changelog := ChangelogItem{
+		Ticket: &Ticket{Key: "PF-1647"},
+		Changes: []*Change{
+			{Field: "Story Points", From: "3", To: "5"},
+			{Field: "Sprint", From: "", To: "DX Sprint 12"},
+			{Field: "labels", From: "spike", To: ""},
+		},
+	}
+	assert.Equal(t, []string{
+		"changed Story Points of PF-1647 from 3 to 5",
+		"set Sprint of PF-1647 to DX Sprint 12",
+		"cleared labels of PF-1647 (was spike)",
+	}, changelog.Describe())
+}
+
+func TestFilterComments(t *testing.T) {
+	at := func(day, hour int) time.Time {
+		return time.Date(2025, 6, day, hour, 0, 0, 0, testLocation)
+	}
+	ticket := Ticket{
+		Key: "DX-75",
+		Comments: []*Comment{
+			{Body: "old", AuthorAccountID: "me", CreatedAt: at(1, 10), UpdatedAt: at(1, 10)},
+			{Body: "edited today", AuthorAccountID: "me", CreatedAt: at(1, 10), UpdatedAt: at(16, 9)},
+			{Body: "theirs", AuthorAccountID: "someone", CreatedAt: at(16, 11), UpdatedAt: at(16, 11)},
+			{Body: "tomorrow", AuthorAccountID: "me", CreatedAt: at(17, 0), UpdatedAt: at(17, 0)},
+		},
+	}
+
+	require.NoError(t, ticket.FilterComments("2025-06-16", "2025-06-16", "me", testLocation))
+	require.Len(t, ticket.Comments, 2)
+	assert.Equal(t, "edited today", ticket.Comments[0].Body)
+	assert.True(t, ticket.Comments[0].Mine)
+	assert.Equal(t, "theirs", ticket.Comments[1].Body)
+	assert.False(t, ticket.Comments[1].Mine)
+	assert.True(t, ticket.HasMyComments())
+}
+
+func TestProposeWorklogs(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2025, 6, 16, hour, minute, 0, 0, time.UTC)
+	}
+	commits := []*gh.Commit{
+		{Ticket: "DX-75", Timestamp: at(10, 0)},
+		{Ticket: "DX-75", Timestamp: at(9, 0)},
+		{Ticket: "DX-75", Timestamp: at(15, 10)},
+		{Ticket: "DX-408", Timestamp: at(13, 0)},
+		{Ticket: "", Timestamp: at(14, 0)},
+	}
+
+	worklogs := ProposeWorklogs(commits, 2*time.Hour, 30*time.Minute, time.UTC)
+	require.Len(t, worklogs, 3)
+
+	assert.Equal(t, "DX-75", worklogs[0].Ticket)
+	assert.Equal(t, at(8, 30), worklogs[0].Started)
+	assert.Equal(t, 90*time.Minute, worklogs[0].TimeSpent)
+
+	assert.Equal(t, "DX-408", worklogs[1].Ticket)
+	assert.Equal(t, 30*time.Minute, worklogs[1].TimeSpent)
+
+	assert.Equal(t, "DX-75", worklogs[2].Ticket)
+	assert.Equal(t, at(14, 40), worklogs[2].Started)
+	assert.Equal(t, 30*time.Minute, worklogs[2].TimeSpent)
+	assert.Equal(t, "DX-75: 30m from 14:40", worklogs[2].String())
+
+	worklogs = ProposeWorklogs(commits, 2*time.Hour, 30*time.Minute, testLocation)
+	assert.Equal(t, "DX-75: 1h 30m from 10:30", worklogs[0].String())
+
+	worklogs = ProposeWorklogs([]*gh.Commit{{Ticket: "DX-408", Timestamp: at(13, 0)}}, 2*time.Hour, 0, time.UTC)
+	require.Len(t, worklogs, 1)
+	assert.Equal(t, at(13, 0), worklogs[0].Started)
+	assert.Equal(t, 15*time.Minute, worklogs[0].TimeSpent)
+}
+
+func TestWorklogs(t *testing.T) {
+	var posted map[string]any
+	client, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /rest/api/2/issue/DX-75/worklog":
+			fmt.Fprint(w, `{"worklogs": [
+				{"author": {"accountId": "me", "displayName": "Me"}, "started": "2025-06-16T09:00:00.000+0000", "timeSpentSeconds": 3600},
+				{"author": {"accountId": "someone"}, "started": "2025-06-16T09:00:00.000+0000", "timeSpentSeconds": 600},
+				{"author": {"accountId": "me"}, "started": "2025-06-15T23:00:00.000+0000", "timeSpentSeconds": 1800},
+				{"author": {"accountId": "me"}, "started": "2025-06-16T22:30:00.000+0000", "timeSpentSeconds": 600},
+				{"author": {"accountId": "me"}, "started": "2025-06-10T09:00:00.000+0000", "timeSpentSeconds": 600}
+			]}`)
+		case "POST /rest/api/2/issue/DX-75/worklog":
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&posted))
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	ticket := Ticket{Key: "DX-75"}
+	require.NoError(t, ticket.FetchWorklogs(client, "2025-06-16", "2025-06-16", "me"))
+	require.Len(t, ticket.Worklogs, 2)
+	assert.Equal(t, "DX-75: 1h from 11:00", ticket.Worklogs[0].String())
+	assert.Equal(t, "DX-75: 30m from 01:00", ticket.Worklogs[1].String())
+
+	worklog := Worklog{Ticket: "DX-75", Started: time.Date(2025, 6, 16, 8, 30, 0, 0, time.UTC), TimeSpent: 90 * time.Minute}
+	require.NoError(t, PostWorklog(client, &worklog))
+	assert.Equal(t, float64(5400), posted["timeSpentSeconds"])
+	assert.Equal(t, "2025-06-16T08:30:00.000+0000", posted["started"])
+	assert.Contains(t, *requests, "POST /rest/api/2/issue/DX-75/worklog")
+}
+
+func TestADFToMarkdown(t *testing.T) {
+	doc := `{"type": "doc", "version": 1, "content": [
+		{"type": "heading", "attrs": {"level": 2}, "content": [{"type": "text", "text": "Context"}]},
+		{"type": "paragraph", "content": [
+			{"type": "text", "text": "Ping "},
+			{"type": "mention", "attrs": {"id": "712020:abc", "text": "@Frank Ittermann"}},
+			{"type": "text", "text": " and "},
+			{"type": "mention", "attrs": {"id": "712020:def"}},
+			{"type": "text", "text": ", see "},
+			{"type": "text", "text": "the docs", "marks": [{"type": "link", "attrs": {"href": "https://example.com"}}]},
+			{"type": "text", "text": " for "},
+			{"type": "text", "text": "values.yaml", "marks": [{"type": "code"}]},
+			{"type": "hardBreak"},
+			{"type": "text", "text": "important", "marks": [{"type": "strong"}]}
+		]},
+		{"type": "bulletList", "content": [
+			{"type": "listItem", "content": [
+				{"type": "paragraph", "content": [{"type": "text", "text": "first"}]},
+				{"type": "orderedList", "content": [
+					{"type": "listItem", "content": [{"type": "paragraph", "content": [{"type": "text", "text": "nested"}]}]}
+				]}
+			]},
+			{"type": "listItem", "content": [{"type": "paragraph", "content": [{"type": "text", "text": "second"}]}]}
+		]},
+		{"type": "codeBlock", "attrs": {"language": "bash"}, "content": [{"type": "text", "text": "make run"}]},
+		{"type": "table", "content": [
+			{"type": "tableRow", "content": [
+				{"type": "tableHeader", "content": [{"type": "paragraph", "content": [{"type": "text", "text": "Env"}]}]},
+				{"type": "tableHeader", "content": [{"type": "paragraph", "content": [{"type": "text", "text": "State"}]}]}
+			]},
+			{"type": "tableRow", "content": [
+				{"type": "tableCell", "content": [{"type": "paragraph", "content": [{"type": "text", "text": "prod"}]}]},
+				{"type": "tableCell", "content": [{"type": "paragraph", "content": [{"type": "status", "attrs": {"text": "DONE"}}]}]}
+			]}
+		]}
+	]}`
+
+	node, err := parseADF([]byte(doc))
+	require.NoError(t, err)
+
+	resolve := func(accountID string) string {
+		return map[string]string{"712020:def": "Kristina Pianykh"}[accountID]
+	}
+	expected := "## Context\n\n" +
+		"Ping @Frank Ittermann and @Kristina Pianykh, see [the docs](https://example.com) for `values.yaml`\n**important**\n\n" +
+		"- first\n  1. nested\n- second\n\n" +
+		"```bash\nmake run\n```\n\n" +
+		"| Env | State |\n| --- | --- |\n| prod | [DONE] |"
+	assert.Equal(t, expected, ADFToMarkdown(node, resolve))
+}
+
+func TestGetTicketByKeyWithADF(t *testing.T) {
+	client, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{
+			"key": "DX-75",
+			"fields": {
+				"summary": "DuneBot use sticky Pull Request comments",
+				"created": "2025-06-16T10:00:00.000+0200",
+				"updated": "2025-06-16T12:00:00.000+0200",
+				"creator": {"displayName": "Kristina Pianykh"},
+				"reporter": {"displayName": "Kristina Pianykh"},
+				"status": {"name": "In Progress"},
+				"description": {"type": "doc", "content": [{"type": "paragraph", "content": [{"type": "text", "text": "Avoid duplicates", "marks": [{"type": "em"}]}]}]},
+				"comment": {"comments": [{
+					"id": "1",
+					"author": {"accountId": "me", "displayName": "Kristina Pianykh"},
+					"created": "2025-06-16T11:00:00.000+0200",
+					"updated": "2025-06-16T11:00:00.000+0200",
+					"body": {"type": "doc", "content": [{"type": "paragraph", "content": [{"type": "text", "text": "Done in PR #159"}]}]}
+				}]}
+			}
+		}`)
+	})
+
+	ticket, err := GetTicketByKey(client, "DX-75")
+	require.NoError(t, err)
+	assert.Equal(t, "_Avoid duplicates_", ticket.Body)
+	require.Len(t, ticket.Comments, 1)
+	assert.Equal(t, "Done in PR #159", ticket.Comments[0].Body)
+	assert.Equal(t, "me", ticket.Comments[0].AuthorAccountID)
+	assert.Equal(t, "Kristina Pianykh", ticket.Comments[0].Author)
+}
+
+func TestSearchIssuesPagination(t *testing.T) {
+	keys := []string{"DX-1", "DX-2", "DX-3", "DX-4", "DX-5"}
+	client, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		startAt, _ := strconv.Atoi(r.URL.Query().Get("startAt"))
+		maxResults, _ := strconv.Atoi(r.URL.Query().Get("maxResults"))
+		page := []string{}
+		for _, key := range keys[startAt:min(startAt+maxResults, len(keys))] {
+			page = append(page, fmt.Sprintf(`{"key": %q}`, key))
+		}
+		fmt.Fprintf(w, `{"issues": [%s], "startAt": %d, "total": %d}`, strings.Join(page, ","), startAt, len(keys))
+	})
+
+	issues, err := SearchIssues(client, "project = DX", &jira.SearchOptions{MaxResults: 2})
+	require.NoError(t, err)
+	require.Len(t, issues, 5)
+	assert.Equal(t, "DX-5", issues[4].Key)
+	assert.Len(t, *requests, 3)
+}
+
+func TestGetTicketsByKeys(t *testing.T) {
+	queries := []string{}
+	client, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		jql := r.URL.Query().Get("jql")
+		queries = append(queries, jql)
+		assert.Equal(t, "warn", r.URL.Query().Get("validateQuery"))
+
+		issues := []string{}
+		for _, key := range []string{"DX-408", "DX-75"} {
+			if strings.Contains(jql, key) {
+				issues = append(issues, strings.Replace(testIssue, `"DX-408"`, strconv.Quote(key), 1))
+			}
+		}
+		fmt.Fprintf(w, `{"issues": [%s], "total": %d}`, strings.Join(issues, ","), len(issues))
+	})
+
+	tickets, err := GetTicketsByKeys(client, []string{"DX-408", "DX-75", "DX-408", "DX-999"})
+	require.NoError(t, err)
+	assert.Len(t, tickets, 2)
+	assert.Equal(t, "DX-75", tickets["DX-75"].Key)
+	assert.Equal(t, []string{"key in (DX-408,DX-75,DX-999)"}, queries)
+
+	// served from the cache
+	ticket, err := GetTicketByKey(client, "DX-75")
+	require.NoError(t, err)
+	assert.Equal(t, "DX-75", ticket.Key)
+	assert.Len(t, queries, 1)
+}
+
+func TestClientCache(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"issues": [%s], "total": 1}`, testIssue)
+	}
+	client, requests := newTestServer(t, handler)
+	other, otherRequests := newTestServer(t, handler)
+
+	for range 2 {
+		_, err := GetTicketsByKeys(client, []string{"DX-408"})
+		require.NoError(t, err)
+	}
+	_, err := GetTicketsByKeys(other, []string{"DX-408"})
+	require.NoError(t, err)
+	assert.Len(t, *requests, 1)
+	assert.Len(t, *otherRequests, 1)
+}
+
+func TestResolveEpicsAndGroupTickets(t *testing.T) {
+	// DX-75 is a story of epic DX-1, DX-76 a sub-task of DX-75
+	client, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "key in (DX-75,OPS-4)", r.URL.Query().Get("jql"))
+		fmt.Fprint(w, `{"issues": [{
+			"key": "DX-75",
+			"fields": {
+				"summary": "Story",
+				"creator": {"displayName": "Kristina Pianykh"},
+				"reporter": {"displayName": "Kristina Pianykh"},
+				"status": {"name": "In Progress"},
+				"parent": {"key": "DX-1", "fields": {"summary": "Epic", "issuetype": {"name": "Epic"}}}
+			}
+		}], "total": 1}`)
+	})
+
+	epic := &TicketRef{Key: "DX-1", Title: "Epic", Type: "Epic"}
+	tickets := []*Ticket{
+		{Key: "DX-76", Title: "Sub-task", Parent: &TicketRef{Key: "DX-75", Title: "Story", Type: "Story"}},
+		{Key: "DX-80", Title: "Task", Parent: epic},
+		{Key: "OPS-3", Title: "Standalone"},
+		{Key: "OPS-5", Title: "Sub-task of a story without epic", Parent: &TicketRef{Key: "OPS-4", Title: "Story", Type: "Story"}},
+	}
+	// OPS-4 is not returned by the search: it stays a plain parent
+	router := NewRouter()
+	router.Add(client, nil)
+	require.NoError(t, ResolveEpics(router, tickets))
+	assert.Equal(t, epic, tickets[0].Epic)
+	assert.Equal(t, epic, tickets[1].Epic)
+	assert.Nil(t, tickets[2].Epic)
+	assert.Nil(t, tickets[3].Epic)
+
+	groups := GroupTickets(tickets)
+	require.Len(t, groups, 3)
+	assert.Equal(t, "DX-1", groups[0].Key)
+	assert.True(t, groups[0].Epic)
+	assert.Equal(t, []*Ticket{tickets[0], tickets[1]}, groups[0].Tickets)
+	assert.Equal(t, "OPS-3", groups[1].Key)
+	assert.Equal(t, "Standalone", groups[1].Title)
+	assert.Equal(t, "OPS-4", groups[2].Key)
+	assert.False(t, groups[2].Epic)
+}
+
+func TestAggPullRequestsByTicketSkipsUnknownTickets(t *testing.T) {
+	client, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "key in (DX-408,DX-999)", r.URL.Query().Get("jql"))
+		fmt.Fprintf(w, `{"issues": [%s], "total": 1}`, testIssue)
+	})
+	router := NewRouter()
+	router.Add(client, nil)
+
+	prs := []*gh.PullRequest{
+		{Owner: "goflink", Repo: "krisss", Number: 31, Ticket: "DX-408"},
+		{Owner: "goflink", Repo: "krisss", Number: 32, Ticket: "DX-999"},
+	}
+	tickets, err := AggPullRequestsByTicket(router, prs)
+	require.NoError(t, err)
+	require.Len(t, tickets, 1)
+	assert.Equal(t, []*gh.PullRequest{prs[0]}, tickets["DX-408"].PullRequests)
+}
+
+func TestGroupTicketsEpicAsParent(t *testing.T) {
+	// the first member only has the epic as parent, e.g. before ResolveEpics
+	epic := &TicketRef{Key: "DX-1", Title: "Epic", Type: "Epic"}
+	tickets := []*Ticket{
+		{Key: "DX-80", Title: "Task", Parent: epic},
+		{Key: "DX-81", Title: "Other task", Parent: epic, Epic: epic},
+	}
+	groups := GroupTickets(tickets)
+	require.Len(t, groups, 1)
+	assert.True(t, groups[0].Epic)
+	assert.Len(t, groups[0].Tickets, 2)
+}
+
+func TestResolveEpicsWithEpicLink(t *testing.T) {
+	// on Data Center, story DX-75 links epic DX-1 through the Epic Link field
+	client, _ := newTestInstance(t, &Instance{DataCenter: true, EpicLinkField: "customfield_10008"}, func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.URL.Query().Get("fields"), "customfield_10008")
+		switch r.URL.Query().Get("jql") {
+		case "key in (DX-75)":
+			fmt.Fprint(w, `{"issues": [{"key": "DX-75", "fields": {
+				"summary": "Story", "creator": {"displayName": "Kristina Pianykh"}, "reporter": {"displayName": "Kristina Pianykh"},
+				"status": {"name": "In Progress"}, "customfield_10008": "DX-1"
+			}}], "total": 1}`)
+		case "key in (DX-1)":
+			fmt.Fprint(w, `{"issues": [{"key": "DX-1", "fields": {
+				"summary": "Developer Experience", "creator": {"displayName": "Kristina Pianykh"}, "reporter": {"displayName": "Kristina Pianykh"},
+				"status": {"name": "In Progress"}, "customfield_10008": null
+			}}], "total": 1}`)
+		default:
+			t.Errorf("unexpected query %s", r.URL.Query().Get("jql"))
+		}
+	})
+	router := NewRouter()
+	router.Add(client, nil)
+
+	tickets := []*Ticket{{Key: "DX-76", Title: "Sub-task", Parent: &TicketRef{Key: "DX-75", Title: "Story", Type: "Sub-task"}}}
+	require.NoError(t, ResolveEpics(router, tickets))
+	assert.Equal(t, &TicketRef{Key: "DX-1", Title: "Developer Experience", Type: "Epic"}, tickets[0].Epic)
+
+	groups := GroupTickets(tickets)
+	require.Len(t, groups, 1)
+	assert.Equal(t, "DX-1", groups[0].Key)
+	assert.Equal(t, "Developer Experience", groups[0].Title)
+	assert.True(t, groups[0].Epic)
+}
+
+func TestGetTicketByKeyWithParent(t *testing.T) {
+	client, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.URL.Query().Get("fields"), "parent")
+		fmt.Fprint(w, strings.Replace(testIssue, `"summary"`,
+			`"parent": {"key": "DX-1", "fields": {"summary": "Developer Experience", "issuetype": {"name": "Epic"}}},
+		"summary"`, 1))
+	})
+
+	ticket, err := GetTicketByKey(client, "DX-408")
+	require.NoError(t, err)
+	assert.Equal(t, &TicketRef{Key: "DX-1", Title: "Developer Experience", Type: "Epic"}, ticket.Parent)
+}
+
+func TestTicketSprint(t *testing.T) {
+	instance := &Instance{SprintField: "customfield_10020", StoryPointsField: "customfield_10016"}
+	client, requests := newTestInstance(t, instance, func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.URL.Query().Get("fields"), ",customfield_10020,customfield_10016")
+		issue := func(key, fields string) string {
+			return strings.Replace(strings.Replace(testIssue, `"DX-408"`, strconv.Quote(key), 1), `"summary"`, fields+`, "summary"`, 1)
+		}
+		fmt.Fprintf(w, `{"issues": [%s, %s, %s], "total": 3}`,
+			issue("DX-408", `"customfield_10020": [
+				{"id": 6, "name": "DX Sprint 6", "state": "closed"},
+				{"id": 7, "name": "DX Sprint 7", "state": "active", "goal": "Ship dev envs",
+				 "startDate": "2025-06-09T08:00:00.000Z", "endDate": "2025-06-23T08:00:00.000Z"}],
+				"customfield_10016": 5`),
+			issue("OPS-7", `"customfield_10020": ["com.atlassian.greenhopper.service.sprint.Sprint@1f[id=12,rapidViewId=3,state=ACTIVE,name=OPS 12,goal=<null>,startDate=2025-06-09T08:00:00.000Z,endDate=2025-06-23T08:00:00.000Z,sequence=12]"]`),
+			// backlog tickets have neither a sprint nor an estimate
+			issue("DX-409", `"customfield_10020": null, "customfield_10016": null`))
+	})
+
+	tickets, err := GetTicketsByKeys(client, []string{"DX-408", "OPS-7", "DX-409"})
+	require.NoError(t, err)
+	assert.Len(t, *requests, 1)
+
+	assert.Equal(t, 7, tickets["DX-408"].Sprint.ID)
+	assert.Equal(t, "Ship dev envs", tickets["DX-408"].Sprint.Goal)
+	assert.Equal(t, time.Date(2025, 6, 23, 8, 0, 0, 0, time.UTC), tickets["DX-408"].Sprint.EndDate)
+	assert.Equal(t, 5.0, tickets["DX-408"].StoryPoints)
+
+	assert.Equal(t, &Sprint{ID: 12, Name: "OPS 12", State: "ACTIVE",
+		StartDate: time.Date(2025, 6, 9, 8, 0, 0, 0, time.UTC), EndDate: time.Date(2025, 6, 23, 8, 0, 0, 0, time.UTC)}, tickets["OPS-7"].Sprint)
+
+	assert.Nil(t, tickets["DX-409"].Sprint)
+	assert.Zero(t, tickets["DX-409"].StoryPoints)
+}
+
+func TestSprintSummary(t *testing.T) {
+	client, requests := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "assignee = currentUser()", r.URL.Query().Get("jql"))
+		issue := `{"key": "%s", "fields": {"summary": "%s", "customfield_10016": %s,
+			"status": {"name": "%s", "statusCategory": {"key": "%s"}}}}`
+		if r.URL.Query().Get("startAt") == "0" {
+			fmt.Fprintf(w, `{"issues": [%s, %s], "total": 3}`,
+				fmt.Sprintf(issue, "DX-2", "Review setup", "3", "Done", "done"),
+				fmt.Sprintf(issue, "DX-1", "Dev envs", "5", "Done", "done"))
+			return
+		}
+		fmt.Fprintf(w, `{"issues": [%s], "total": 3}`,
+			fmt.Sprintf(issue, "DX-3", "Docs", "null", "In Progress", "indeterminate"))
+	})
+
+	tickets, err := GetSprintTickets(client, 7, "assignee = currentUser()", "customfield_10016")
+	require.NoError(t, err)
+	assert.Len(t, *requests, 2)
+	assert.Equal(t, "/rest/agile/1.0/sprint/7/issue", strings.Fields((*requests)[0])[1])
+	require.Len(t, tickets, 3)
+
+	sprint := &Sprint{
+		ID: 7, Name: "DX Sprint 7", Goal: "Ship dev envs",
+		StartDate: time.Date(2025, 6, 9, 8, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2025, 6, 23, 8, 0, 0, 0, time.UTC),
+	}
+	expected := `Sprint DX Sprint 7 (2025-06-09..2025-06-23)
+Goal: Ship dev envs
+- Completed: 8 points in 2 tickets
+- Carried over: 0 points in 1 tickets
+- Done: 100% of committed points
+
+Completed:
+- DX-1 Dev envs [Done] (5 points)
+- DX-2 Review setup [Done] (3 points)
+
+Carried over:
+- DX-3 Docs [In Progress] (0 points)
+`
+	assert.Equal(t, expected, SummarizeSprint(sprint, tickets).String())
+}
+
+func TestGetBoard(t *testing.T) {
+	var jql string
+	client, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		jql = r.URL.Query().Get("jql")
+		issues := []string{}
+		for _, ticket := range []struct{ key, summary, status string }{
+			{"OPS-1", "Rotate keys", "Doing"},
+			{"OPS-2", "Fix alert | page", "Doing"},
+			{"OPS-3", "Upgrade cluster", "ready"},
+			{"OPS-4", "Old status", "Archived"},
+		} {
+			issue := strings.NewReplacer(`"DX-408"`, strconv.Quote(ticket.key),
+				`"Setup easy and composable Developer Environments"`, strconv.Quote(ticket.summary),
+				`"In Progress"`, strconv.Quote(ticket.status)).Replace(testIssue)
+			issues = append(issues, issue)
+		}
+		fmt.Fprintf(w, `{"issues": [%s], "total": %d}`, strings.Join(issues, ","), len(issues))
+	})
+
+	workflow := NewWorkflow(map[string]string{"Ready": "To Do", "Doing": "In Progress", "Parked": "Icebox"})
+	board, err := GetBoard(client, "OPS", workflow)
+	require.NoError(t, err)
+	assert.Contains(t, jql, `project = "OPS"`)
+	assert.Contains(t, jql, `status IN ("Doing", "Parked", "Ready")`)
+
+	assert.Equal(t, []Column{ColumnToDo, ColumnInProgress, "Icebox"}, board.OrderedColumns())
+	assert.Len(t, board.Columns[ColumnInProgress], 2)
+	assert.Empty(t, board.Columns["Icebox"])
+
+	var out strings.Builder
+	board.Render(&out)
+	expected := `| To Do (1) | In Progress (2) | Icebox (0) |
+| --- | --- | --- |
+| OPS-3 Upgrade cluster | OPS-1 Rotate keys |  |
+|  | OPS-2 Fix alert \| page |  |
+`
+	assert.Equal(t, expected, out.String())
+}
+
+func TestDataCenterInstance(t *testing.T) {
+	var auth, path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth, path = r.Header.Get("Authorization"), r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		// Data Center returns plain text and usernames instead of accountIds
+		fmt.Fprint(w, `{"key": "OPS-7", "fields": {
+			"summary": "Rotate keys",
+			"description": "Plain *wiki* text",
+			"creator": {"name": "kpianykh"},
+			"reporter": {"name": "kpianykh"},
+			"status": {"name": "Open"},
+			"comment": {"comments": [{"id": "1", "author": {"name": "kpianykh", "displayName": "Kristina Pianykh"},
+				"body": "On it", "created": "2025-06-16T10:00:00.000+0200", "updated": "2025-06-16T10:00:00.000+0200"}]}
+		}}`)
+	}))
+	t.Cleanup(server.Close)
+
+	_, err := InitJiraClient(&Instance{URL: server.URL, DataCenter: true})
+	assert.Error(t, err)
+
+	client, err := InitJiraClient(&Instance{URL: server.URL, DataCenter: true, Token: "pat"})
+	require.NoError(t, err)
+	ticket, err := GetTicketByKey(client, "OPS-7")
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer pat", auth)
+	assert.Equal(t, "/rest/api/2/issue/OPS-7", path)
+	assert.Equal(t, "Plain *wiki* text", ticket.Body)
+	assert.Equal(t, "kpianykh", ticket.Comments[0].AuthorAccountID)
+	assert.Equal(t, "Kristina Pianykh", ticket.Comments[0].Author)
+}
+
+func TestRouter(t *testing.T) {
+	handler := func(instance string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			issues := []string{}
+			for _, key := range []string{"DX-1", "OPS-2", "ops-3"} {
+				if strings.Contains(r.URL.Query().Get("jql"), key) {
+					issue := strings.NewReplacer(`"DX-408"`, strconv.Quote(key),
+						`"Setup easy and composable Developer Environments"`, strconv.Quote(instance)).Replace(testIssue)
+					issues = append(issues, issue)
+				}
+			}
+			fmt.Fprintf(w, `{"issues": [%s], "total": %d}`, strings.Join(issues, ","), len(issues))
+		}
+	}
+	cloud, cloudRequests := newTestServer(t, handler("cloud"))
+	dc, dcRequests := newTestServer(t, handler("dc"))
+
+	router := NewRouter()
+	router.Add(dc, []string{"OPS"})
+	router.Add(cloud, nil)
+	assert.Equal(t, cloud, router.Default())
+	assert.Equal(t, dc, router.Client("OPS-2"))
+	assert.Equal(t, dc, router.ProjectClient("ops"))
+	assert.Equal(t, cloud, router.Client("PF-1"))
+
+	prs := []*gh.PullRequest{{ID: 1, Ticket: "DX-1"}, {ID: 2, Ticket: "OPS-2"}, {ID: 3, Ticket: "ops-3"}}
+	tickets, err := AggPullRequestsByTicket(router, prs)
+	require.NoError(t, err)
+	assert.Equal(t, "cloud", tickets["DX-1"].Title)
+	assert.Equal(t, "dc", tickets["OPS-2"].Title)
+	assert.Equal(t, "dc", tickets["ops-3"].Title)
+	assert.Len(t, *cloudRequests, 1)
+	assert.Len(t, *dcRequests, 1)
+}
+
+func TestTicketFields(t *testing.T) {
+	instance := &Instance{CustomFields: map[string]string{"Team": "customfield_10001", "Severity": "customfield_10002"}}
+	var fields string
+	client, _ := newTestInstance(t, instance, func(w http.ResponseWriter, r *http.Request) {
+		fields = r.URL.Query().Get("fields")
+		issue := strings.Replace(testIssue, `"key"`, `"self": "https://goflink.atlassian.net/rest/api/3/issue/10001", "key"`, 1)
+		fmt.Fprint(w, strings.Replace(issue, `"summary"`, `"priority": {"name": "Highest"},
+		"issuetype": {"name": "Incident"},
+		"labels": ["oncall", "postmortem"],
+		"components": [{"name": "ci"}],
+		"fixVersions": [{"name": "2025.06"}],
+		"issuelinks": [
+			{"type": {"name": "Blocks", "inward": "is blocked by", "outward": "blocks"},
+			 "outwardIssue": {"key": "DX-76", "fields": {"summary": "Roll out", "status": {"name": "To Do"}}}},
+			{"type": {"name": "Relates", "inward": "relates to", "outward": "relates to"},
+			 "inwardIssue": {"key": "OPS-1", "fields": {"summary": "Alerting"}}}
+		],
+		"customfield_10001": {"value": "Developer Experience"},
+		"customfield_10002": null,
+		"summary"`, 1))
+	})
+
+	ticket, err := GetTicketByKey(client, "DX-408")
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(fields, ",issuelinks,fixVersions,customfield_10001,customfield_10002"))
+	assert.Equal(t, "https://goflink.atlassian.net/browse/DX-408", ticket.URL)
+	assert.Equal(t, "Highest", ticket.Priority)
+	assert.Equal(t, "Incident", ticket.Type)
+	assert.Equal(t, []string{"oncall", "postmortem"}, ticket.Labels)
+	assert.Equal(t, []string{"ci"}, ticket.Components)
+	assert.Equal(t, []string{"2025.06"}, ticket.FixVersions)
+	assert.Equal(t, []*TicketLink{
+		{Relation: "blocks", Key: "DX-76", Title: "Roll out", Status: "To Do"},
+		{Relation: "relates to", Key: "OPS-1", Title: "Alerting"},
+	}, ticket.Links)
+	assert.Equal(t, []*TicketLink{ticket.Links[0]}, ticket.Unblocked())
+	assert.Equal(t, map[string]string{"Team": "Developer Experience"}, ticket.CustomFields)
+}
+
+func TestCustomFieldValue(t *testing.T) {
+	tests := []struct {
+		value    any
+		expected string
+	}{
+		{nil, ""},
+		{"text", "text"},
+		{float64(8), "8"},
+		{map[string]any{"displayName": "Frank Ittermann"}, "Frank Ittermann"},
+		{[]any{map[string]any{"value": "a"}, map[string]any{"name": "b"}}, "a, b"},
+		{map[string]any{"id": "1"}, `{"id":"1"}`},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, customFieldValue(tt.value))
+	}
+}
+
 func TestGetIssue(t *testing.T) {
 	key := "DX-75"
-	client, err := InitJiraClient()
+	client, err := InitJiraClient(&Instance{
+		URL:      "https://goflink.atlassian.net",
+		Username: os.Getenv("JIRA_USERNAME"),
+		Token:    os.Getenv("JIRA_API_TOKEN"),
+	})
 	assert.NoError(t, err)
 
 	jTicket, err := GetIssue(client, key)
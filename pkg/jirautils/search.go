@@ -0,0 +1,144 @@
+package jirautils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+const (
+	searchPageSize = 100
+	// bulkBatchSize keeps "key in (...)" queries well below URL length limits
+	bulkBatchSize = 50
+)
+
+// cacheIssue remembers an issue fetched with the default ticket fields for
+// the rest of the run.
+func (c *Client) cacheIssue(issue *Issue) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.issues[issue.Key] = issue
+}
+
+func (c *Client) cachedIssue(key string) (*Issue, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	issue, ok := c.issues[key]
+	return issue, ok
+}
+
+// searchPage is one page of a v3 search. Issues stay raw so that their ADF
+// fields can be decoded by decodeIssue.
+type searchPage struct {
+	Issues     []json.RawMessage `json:"issues"`
+	StartAt    int               `json:"startAt"`
+	MaxResults int               `json:"maxResults"`
+	Total      int               `json:"total"`
+}
+
+// SearchIssues returns every issue matching the JQL, following all result
+// pages. Fields default to the ticket fields when opts does not set them.
+func SearchIssues(client *Client, jql string, opts *jira.SearchOptions) ([]*Issue, error) {
+	if opts == nil {
+		opts = &jira.SearchOptions{}
+	}
+	fields := opts.Fields
+	if len(fields) == 0 {
+		fields = client.requestedFields()
+	}
+	pageSize := opts.MaxResults
+	if pageSize == 0 {
+		pageSize = searchPageSize
+	}
+
+	issues := []*Issue{}
+	for startAt := opts.StartAt; ; {
+		values := url.Values{}
+		values.Set("jql", jql)
+		values.Set("fields", strings.Join(fields, ","))
+		values.Set("startAt", strconv.Itoa(startAt))
+		values.Set("maxResults", strconv.Itoa(pageSize))
+		if opts.Expand != "" {
+			values.Set("expand", opts.Expand)
+		}
+		if opts.ValidateQuery != "" {
+			values.Set("validateQuery", opts.ValidateQuery)
+		}
+
+		req, err := client.NewRequest("GET", apiPath(client, "search?"+values.Encode()), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to prepare a search request for '%s': %w", jql, err)
+		}
+		page := searchPage{}
+		if _, err := client.Do(req, &page); err != nil {
+			return nil, fmt.Errorf("failed to search issues with '%s': %w", jql, err)
+		}
+
+		for _, raw := range page.Issues {
+			issue, err := decodeIssue(raw)
+			if err != nil {
+				return nil, err
+			}
+			issues = append(issues, issue)
+		}
+
+		startAt += len(page.Issues)
+		if len(page.Issues) == 0 || startAt >= page.Total {
+			break
+		}
+	}
+	return issues, nil
+}
+
+// GetTicketsByKeys fetches the tickets with a handful of "key in (...)"
+// searches. Tickets fetched before in this run are taken from the cache, keys
+// that do not exist are left out of the result.
+func GetTicketsByKeys(client *Client, keys []string) (map[string]*Ticket, error) {
+	missing := []string{}
+	for _, key := range keys {
+		if _, ok := client.cachedIssue(key); !ok && !containsKey(missing, key) {
+			missing = append(missing, key)
+		}
+	}
+
+	for start := 0; start < len(missing); start += bulkBatchSize {
+		batch := missing[start:min(start+bulkBatchSize, len(missing))]
+		jql := fmt.Sprintf("key in (%s)", strings.Join(batch, ","))
+		// "warn" makes Jira skip unknown keys instead of rejecting the query
+		issues, err := SearchIssues(client, jql, &jira.SearchOptions{ValidateQuery: "warn"})
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range issues {
+			client.cacheIssue(issue)
+		}
+	}
+
+	resolve := newUserResolver(client)
+	tickets := map[string]*Ticket{}
+	for _, key := range keys {
+		issue, ok := client.cachedIssue(key)
+		if !ok {
+			continue
+		}
+		ticket, err := client.newTicket(issue, resolve)
+		if err != nil {
+			return nil, err
+		}
+		tickets[key] = ticket
+	}
+	return tickets, nil
+}
+
+func containsKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
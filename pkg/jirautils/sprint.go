@@ -0,0 +1,289 @@
+package jirautils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sprint is a sprint of a Jira Software board.
+type Sprint struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	State     string    `json:"state"`
+	Goal      string    `json:"goal,omitempty"`
+	StartDate time.Time `json:"startDate"`
+	EndDate   time.Time `json:"endDate"`
+}
+
+// agileIssue is an issue of the Agile API, which adds the sprint fields. The
+// story points live in a custom field whose ID differs between Jira sites.
+type agileIssue struct {
+	Key    string                     `json:"key"`
+	Fields map[string]json.RawMessage `json:"fields"`
+}
+
+func (i *agileIssue) storyPoints(field string) (float64, error) {
+	raw, ok := i.Fields[field]
+	if !ok || string(raw) == "null" {
+		return 0, nil
+	}
+	var points float64
+	if err := json.Unmarshal(raw, &points); err != nil {
+		return 0, fmt.Errorf("failed to decode story points of %s from %s: %w", i.Key, field, err)
+	}
+	return points, nil
+}
+
+func (i *agileIssue) stringField(path ...string) string {
+	raw := i.Fields[path[0]]
+	for _, key := range path[1:] {
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &object); err != nil {
+			return ""
+		}
+		raw = object[key]
+	}
+	var s string
+	json.Unmarshal(raw, &s)
+	return s
+}
+
+// GetActiveSprint returns the active sprint of a board, or nil if there is none.
+func GetActiveSprint(client *Client, boardID int) (*Sprint, error) {
+	req, err := client.NewRequest("GET", fmt.Sprintf("rest/agile/1.0/board/%d/sprint?state=active", boardID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare a request for the sprints of board %d: %w", boardID, err)
+	}
+
+	var result struct {
+		Values []*Sprint `json:"values"`
+	}
+	if _, err := client.Do(req, &result); err != nil {
+		return nil, fmt.Errorf("failed to fetch the active sprint of board %d: %w", boardID, err)
+	}
+	if len(result.Values) == 0 {
+		return nil, nil
+	}
+	return result.Values[0], nil
+}
+
+// GetSprint returns the sprint with the given ID.
+func GetSprint(client *Client, sprintID int) (*Sprint, error) {
+	req, err := client.NewRequest("GET", fmt.Sprintf("rest/agile/1.0/sprint/%d", sprintID), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare a request for sprint %d: %w", sprintID, err)
+	}
+
+	sprint := Sprint{}
+	if _, err := client.Do(req, &sprint); err != nil {
+		return nil, fmt.Errorf("failed to fetch sprint %d: %w", sprintID, err)
+	}
+	return &sprint, nil
+}
+
+// dataCenterSprintField matches the attributes of a sprint rendered as text by
+// Data Center, e.g. "...Sprint@1f[id=7,state=ACTIVE,name=DX Sprint 7,...]".
+var dataCenterSprintField = regexp.MustCompile(`[\[,](\w+)=`)
+
+// sprintFromField reads the sprint of a ticket from the value of the sprint
+// field: a list of sprint objects on Cloud, a list of texts on Data Center.
+// The active sprint wins over closed and future ones; tickets without a
+// sprint, or with a value that cannot be read, have none.
+func sprintFromField(value any) *Sprint {
+	values, ok := value.([]any)
+	if !ok {
+		return nil
+	}
+
+	var sprint *Sprint
+	for _, v := range values {
+		var s *Sprint
+		switch v := v.(type) {
+		case map[string]any:
+			s = newSprint(v)
+		case string:
+			s = parseDataCenterSprint(v)
+		}
+		if s == nil {
+			continue
+		}
+		if sprint == nil || strings.EqualFold(s.State, "active") || !strings.EqualFold(sprint.State, "active") {
+			sprint = s
+		}
+	}
+	return sprint
+}
+
+func newSprint(fields map[string]any) *Sprint {
+	sprint := Sprint{}
+	if id, ok := fields["id"].(float64); ok {
+		sprint.ID = int(id)
+	}
+	sprint.Name, _ = fields["name"].(string)
+	sprint.State, _ = fields["state"].(string)
+	sprint.Goal, _ = fields["goal"].(string)
+	if start, ok := fields["startDate"].(string); ok {
+		sprint.StartDate, _ = time.Parse(time.RFC3339, start)
+	}
+	if end, ok := fields["endDate"].(string); ok {
+		sprint.EndDate, _ = time.Parse(time.RFC3339, end)
+	}
+	if sprint.Name == "" {
+		return nil
+	}
+	return &sprint
+}
+
+func parseDataCenterSprint(text string) *Sprint {
+	start := strings.Index(text, "[")
+	if start < 0 || !strings.HasSuffix(text, "]") {
+		return nil
+	}
+	text = text[start : len(text)-1]
+
+	fields := map[string]any{}
+	matches := dataCenterSprintField.FindAllStringSubmatchIndex(text, -1)
+	for i, match := range matches {
+		end := len(text)
+		if i+1 < len(matches) {
+			end = matches[i+1][0]
+		}
+		value := text[match[1]:end]
+		if value == "<null>" {
+			continue
+		}
+		fields[text[match[2]:match[3]]] = value
+	}
+	if id, ok := fields["id"].(string); ok {
+		if n, err := strconv.Atoi(id); err == nil {
+			fields["id"] = float64(n)
+		}
+	}
+	return newSprint(fields)
+}
+
+// SprintTicket is a ticket of a sprint with its story points.
+type SprintTicket struct {
+	Key         string
+	Title       string
+	Status      string
+	Done        bool
+	StoryPoints float64
+}
+
+// GetSprintTickets returns the tickets of a sprint matching the JQL, e.g.
+// "assignee = currentUser()", following all result pages.
+func GetSprintTickets(client *Client, sprintID int, jql, storyPointsField string) ([]*SprintTicket, error) {
+	tickets := []*SprintTicket{}
+	for startAt := 0; ; {
+		values := url.Values{}
+		values.Set("jql", jql)
+		values.Set("fields", strings.Join([]string{"summary", "status", storyPointsField}, ","))
+		values.Set("startAt", strconv.Itoa(startAt))
+		values.Set("maxResults", strconv.Itoa(searchPageSize))
+
+		req, err := client.NewRequest("GET", fmt.Sprintf("rest/agile/1.0/sprint/%d/issue?%s", sprintID, values.Encode()), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to prepare a request for the issues of sprint %d: %w", sprintID, err)
+		}
+
+		var page struct {
+			Issues []*agileIssue `json:"issues"`
+			Total  int           `json:"total"`
+		}
+		if _, err := client.Do(req, &page); err != nil {
+			return nil, fmt.Errorf("failed to fetch the issues of sprint %d: %w", sprintID, err)
+		}
+
+		for _, issue := range page.Issues {
+			points, err := issue.storyPoints(storyPointsField)
+			if err != nil {
+				return nil, err
+			}
+			tickets = append(tickets, &SprintTicket{
+				Key:         issue.Key,
+				Title:       issue.stringField("summary"),
+				Status:      issue.stringField("status", "name"),
+				Done:        issue.stringField("status", "statusCategory", "key") == "done",
+				StoryPoints: points,
+			})
+		}
+
+		startAt += len(page.Issues)
+		if len(page.Issues) == 0 || startAt >= page.Total {
+			break
+		}
+	}
+	return tickets, nil
+}
+
+// SprintSummary compares the completed scope of a sprint with the scope that
+// is not done and carries over into the next sprint.
+type SprintSummary struct {
+	Sprint      *Sprint
+	Completed   []*SprintTicket
+	CarriedOver []*SprintTicket
+}
+
+func SummarizeSprint(sprint *Sprint, tickets []*SprintTicket) *SprintSummary {
+	summary := SprintSummary{Sprint: sprint, Completed: []*SprintTicket{}, CarriedOver: []*SprintTicket{}}
+	for _, ticket := range tickets {
+		if ticket.Done {
+			summary.Completed = append(summary.Completed, ticket)
+		} else {
+			summary.CarriedOver = append(summary.CarriedOver, ticket)
+		}
+	}
+	sort.Slice(summary.Completed, func(i, j int) bool { return summary.Completed[i].Key < summary.Completed[j].Key })
+	sort.Slice(summary.CarriedOver, func(i, j int) bool { return summary.CarriedOver[i].Key < summary.CarriedOver[j].Key })
+	return &summary
+}
+
+func sumPoints(tickets []*SprintTicket) float64 {
+	total := 0.0
+	for _, ticket := range tickets {
+		total += ticket.StoryPoints
+	}
+	return total
+}
+
+func formatPoints(points float64) string {
+	return strconv.FormatFloat(points, 'f', -1, 64)
+}
+
+func (s *SprintSummary) String() string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("Sprint %s (%s..%s)\n",
+		s.Sprint.Name, s.Sprint.StartDate.Format("2006-01-02"), s.Sprint.EndDate.Format("2006-01-02")))
+	if s.Sprint.Goal != "" {
+		builder.WriteString(fmt.Sprintf("Goal: %s\n", s.Sprint.Goal))
+	}
+
+	completed, carriedOver := sumPoints(s.Completed), sumPoints(s.CarriedOver)
+	builder.WriteString(fmt.Sprintf("- Completed: %s points in %d tickets\n", formatPoints(completed), len(s.Completed)))
+	builder.WriteString(fmt.Sprintf("- Carried over: %s points in %d tickets\n", formatPoints(carriedOver), len(s.CarriedOver)))
+	if total := completed + carriedOver; total > 0 {
+		builder.WriteString(fmt.Sprintf("- Done: %.0f%% of committed points\n", 100*completed/total))
+	}
+
+	for _, section := range []struct {
+		title   string
+		tickets []*SprintTicket
+	}{{"Completed", s.Completed}, {"Carried over", s.CarriedOver}} {
+		if len(section.tickets) == 0 {
+			continue
+		}
+		builder.WriteString(fmt.Sprintf("\n%s:\n", section.title))
+		for _, ticket := range section.tickets {
+			builder.WriteString(fmt.Sprintf("- %s %s [%s] (%s points)\n",
+				ticket.Key, ticket.Title, ticket.Status, formatPoints(ticket.StoryPoints)))
+		}
+	}
+	return builder.String()
+}
@@ -0,0 +1,142 @@
+package jirautils
+
+import (
+	"fmt"
+	"perf/pkg/gh"
+	"sort"
+	"time"
+
+	"github.com/andygrunwald/go-jira"
+)
+
+type Worklog struct {
+	Ticket          string
+	Author          string
+	AuthorAccountID string `json:"-"`
+	Started         time.Time
+	TimeSpent       time.Duration
+	Comment         string
+}
+
+func (w *Worklog) String() string {
+	return fmt.Sprintf("%s: %s from %s", w.Ticket, formatTimeSpent(w.TimeSpent), w.Started.Format("15:04"))
+}
+
+// formatTimeSpent renders a duration in Jira notation, e.g. "1h 30m".
+func formatTimeSpent(d time.Duration) string {
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+	switch {
+	case hours == 0:
+		return fmt.Sprintf("%dm", minutes)
+	case minutes == 0:
+		return fmt.Sprintf("%dh", hours)
+	default:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	}
+}
+
+// FetchWorklogs attaches the worklogs the account logged on the ticket with a
+// start between from and to (both inclusive).
+func (t *Ticket) FetchWorklogs(client *Client, from, to, accountID string) error {
+	start, end, err := parseWindow(from, to, client.location)
+	if err != nil {
+		return err
+	}
+
+	jWorklog, _, err := client.Issue.GetWorklogs(t.Key)
+	if err != nil {
+		return fmt.Errorf("failed to get worklogs of ticket %s: %w", t.Key, err)
+	}
+
+	worklogs := []*Worklog{}
+	for _, record := range jWorklog.Worklogs {
+		if record.Author == nil || UserID(record.Author) != accountID || record.Started == nil {
+			continue
+		}
+		started := time.Time(*record.Started).In(client.location)
+		if !inWindow(started, start, end) {
+			continue
+		}
+		worklogs = append(worklogs, &Worklog{
+			Ticket:          t.Key,
+			Author:          record.Author.DisplayName,
+			AuthorAccountID: UserID(record.Author),
+			Started:         started,
+			TimeSpent:       time.Duration(record.TimeSpentSeconds) * time.Second,
+			Comment:         record.Comment,
+		})
+	}
+	t.Worklogs = worklogs
+	return nil
+}
+
+// PostWorklog records the worklog on its ticket.
+func PostWorklog(client *Client, worklog *Worklog) error {
+	started := jira.Time(worklog.Started)
+	record := jira.WorklogRecord{
+		Comment:          worklog.Comment,
+		Started:          &started,
+		TimeSpentSeconds: int(worklog.TimeSpent.Seconds()),
+	}
+	if _, _, err := client.Issue.AddWorklogRecord(worklog.Ticket, &record); err != nil {
+		return fmt.Errorf("failed to add worklog to ticket %s: %w", worklog.Ticket, err)
+	}
+	return nil
+}
+
+// ProposeWorklogs clusters commit times per ticket into work sessions. Commits
+// less than maxGap apart belong to the same session, and every session starts
+// lead before its first commit. Durations are rounded up to 15 minutes, so
+// that a single commit without lead still logs 15 minutes, and the sessions
+// start in the given location. Commits without a ticket are
+// ignored.
+func ProposeWorklogs(commits []*gh.Commit, maxGap, lead time.Duration, loc *time.Location) []*Worklog {
+	byTicket := map[string][]time.Time{}
+	for _, c := range commits {
+		if c.Ticket == "" {
+			continue
+		}
+		byTicket[c.Ticket] = append(byTicket[c.Ticket], c.Timestamp)
+	}
+
+	worklogs := []*Worklog{}
+	for ticket, times := range byTicket {
+		sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+		sessionStart, sessionEnd := times[0], times[0]
+		flush := func() {
+			started := sessionStart.Add(-lead).In(loc)
+			worklogs = append(worklogs, &Worklog{
+				Ticket:    ticket,
+				Started:   started,
+				TimeSpent: max(roundUp(sessionEnd.Sub(started), worklogUnit), worklogUnit),
+				Comment:   "Proposed from commit activity",
+			})
+		}
+		for _, t := range times[1:] {
+			if t.Sub(sessionEnd) > maxGap {
+				flush()
+				sessionStart = t
+			}
+			sessionEnd = t
+		}
+		flush()
+	}
+
+	sort.Slice(worklogs, func(i, j int) bool {
+		return worklogs[i].Started.Before(worklogs[j].Started)
+	})
+	return worklogs
+}
+
+// worklogUnit is the granularity and the minimum of proposed worklogs.
+const worklogUnit = 15 * time.Minute
+
+func roundUp(d, unit time.Duration) time.Duration {
+	rounded := d.Truncate(unit)
+	if rounded < d {
+		rounded += unit
+	}
+	return rounded
+}
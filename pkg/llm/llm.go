@@ -0,0 +1,99 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"perf/pkg/anthropic"
+	"perf/pkg/config"
+	"perf/pkg/openai"
+)
+
+const (
+	ProviderOpenAI           = "openai"
+	ProviderOpenAICompatible = "openai-compatible"
+	ProviderAnthropic        = "anthropic"
+)
+
+// DefaultCompatibleBaseURL is the OpenAI-compatible endpoint of a local Ollama.
+const DefaultCompatibleBaseURL = "http://localhost:11434/v1"
+
+// Summarizer turns the collected activity into the report, following the
+// instructions of the system prompt.
+type Summarizer interface {
+	Summarize(ctx context.Context, system, input string) (string, error)
+}
+
+// StructuredSummarizer is a Summarizer whose provider can constrain the
+// answer to a JSON schema.
+type StructuredSummarizer interface {
+	Summarizer
+	SummarizeJSON(ctx context.Context, system, input, schema string) (string, error)
+}
+
+// New creates the summarizer of the configured provider. Local
+// OpenAI-compatible servers usually do not check the API key, so it is
+// optional for them.
+func New(cfg config.LLM) (Summarizer, error) {
+	provider := providerOf(cfg)
+
+	keyEnv := cfg.APIKeyEnv
+	if keyEnv == "" {
+		switch provider {
+		case ProviderAnthropic:
+			keyEnv = "ANTHROPIC_API_KEY"
+		default:
+			keyEnv = "OPENAI_API_KEY"
+		}
+	}
+	apiKey, hasKey := os.LookupEnv(keyEnv)
+
+	switch provider {
+	case ProviderOpenAI:
+		if !hasKey {
+			return nil, fmt.Errorf("missing %s", keyEnv)
+		}
+		return openai.NewSummarizer(openai.NewClient(apiKey, cfg.BaseURL), cfg.Model), nil
+	case ProviderOpenAICompatible:
+		if cfg.Model == "" {
+			return nil, fmt.Errorf("provider %s needs a model", provider)
+		}
+		if !hasKey {
+			apiKey = "local"
+		}
+		baseURL := cfg.BaseURL
+		if baseURL == "" {
+			baseURL = DefaultCompatibleBaseURL
+		}
+		return openai.NewSummarizer(openai.NewClient(apiKey, baseURL), cfg.Model), nil
+	case ProviderAnthropic:
+		if !hasKey {
+			return nil, fmt.Errorf("missing %s", keyEnv)
+		}
+		return anthropic.NewSummarizer(apiKey, cfg.BaseURL, cfg.Model), nil
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q", provider)
+	}
+}
+
+func providerOf(cfg config.LLM) string {
+	if cfg.Provider == "" {
+		return ProviderOpenAI
+	}
+	return cfg.Provider
+}
+
+// ModelName names the provider and the model the summarizer of the
+// configuration uses, defaults included, e.g. "openai/gpt-4.1-mini".
+func ModelName(cfg config.LLM) string {
+	provider, model := providerOf(cfg), cfg.Model
+	if model == "" {
+		switch provider {
+		case ProviderOpenAI:
+			model = openai.DefaultModel
+		case ProviderAnthropic:
+			model = anthropic.DefaultModel
+		}
+	}
+	return provider + "/" + model
+}
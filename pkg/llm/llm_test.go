@@ -0,0 +1,291 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"perf/pkg/anthropic"
+	"perf/pkg/config"
+	"perf/pkg/openai"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	t.Setenv("PERF_TEST_KEY", "key")
+
+	tests := []struct {
+		name     string
+		cfg      config.LLM
+		expected Summarizer
+		wantErr  bool
+	}{
+		{name: "openai", cfg: config.LLM{APIKeyEnv: "PERF_TEST_KEY"}, expected: &openai.Summarizer{}},
+		{name: "openai without key", cfg: config.LLM{APIKeyEnv: "PERF_TEST_MISSING"}, wantErr: true},
+		{name: "ollama without key", cfg: config.LLM{Provider: ProviderOpenAICompatible, Model: "llama3.1", APIKeyEnv: "PERF_TEST_MISSING"}, expected: &openai.Summarizer{}},
+		{name: "compatible without model", cfg: config.LLM{Provider: ProviderOpenAICompatible}, wantErr: true},
+		{name: "anthropic", cfg: config.LLM{Provider: ProviderAnthropic, APIKeyEnv: "PERF_TEST_KEY"}, expected: &anthropic.Summarizer{}},
+		{name: "unknown", cfg: config.LLM{Provider: "gemini"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summarizer, err := New(tt.cfg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.IsType(t, tt.expected, summarizer)
+		})
+	}
+}
+
+func TestModelName(t *testing.T) {
+	tests := []struct {
+		cfg      config.LLM
+		expected string
+	}{
+		{cfg: config.LLM{}, expected: "openai/" + openai.DefaultModel},
+		{cfg: config.LLM{Provider: ProviderAnthropic}, expected: "anthropic/" + anthropic.DefaultModel},
+		{cfg: config.LLM{Provider: ProviderOpenAICompatible, Model: "llama3.1"}, expected: "openai-compatible/llama3.1"},
+		{cfg: config.LLM{Model: "gpt-4.1"}, expected: "openai/gpt-4.1"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, ModelName(tt.cfg))
+	}
+}
+
+func TestOpenAICompatible(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices": [{"index": 0, "message": {"role": "assistant", "content": "- Did things"}}]}`)
+	}))
+	defer server.Close()
+
+	summarizer, err := New(config.LLM{Provider: ProviderOpenAICompatible, BaseURL: server.URL + "/v1/", Model: "llama3.1"})
+	require.NoError(t, err)
+	output, err := summarizer.Summarize(context.Background(), "be brief", "activity")
+	require.NoError(t, err)
+	assert.Equal(t, "- Did things", output)
+}
+
+type call struct {
+	system string
+	input  string
+	schema string
+}
+
+// fakeSummarizer answers with the given answers in turn, and with the first
+// line of the input once they are used up.
+type fakeSummarizer struct {
+	calls   []call
+	answers []string
+}
+
+func (f *fakeSummarizer) Summarize(ctx context.Context, system, input string) (string, error) {
+	f.calls = append(f.calls, call{system: system, input: input})
+	if len(f.answers) > 0 {
+		answer := f.answers[0]
+		f.answers = f.answers[1:]
+		return answer, nil
+	}
+	return "summary of " + strings.SplitN(input, "\n", 2)[0], nil
+}
+
+// fakeStructuredSummarizer is a fakeSummarizer with structured output.
+type fakeStructuredSummarizer struct {
+	fakeSummarizer
+}
+
+func (f *fakeStructuredSummarizer) SummarizeJSON(ctx context.Context, system, input, schema string) (string, error) {
+	output, err := f.Summarize(ctx, system, input)
+	f.calls[len(f.calls)-1].schema = schema
+	return output, err
+}
+
+func TestMapReduce(t *testing.T) {
+	header := "# Activity 2025-06-16\n"
+	parts := []string{
+		"## DX-1\n" + strings.Repeat("a", 400),
+		"## DX-2\n" + strings.Repeat("b", 400),
+		"## DX-3\n" + strings.Repeat("c", 400),
+	}
+	doc := &Document{Text: header + strings.Join(parts, ""), Header: header, Parts: parts}
+
+	tests := []struct {
+		name           string
+		maxInputTokens int
+		stages         []string
+	}{
+		{name: "fits", maxInputTokens: 1000, stages: []string{"report"}},
+		{name: "map and reduce", maxInputTokens: 200, stages: []string{"map", "map", "map", "report"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeSummarizer{}
+			m := MapReduce{Summarizer: fake, MaxInputTokens: tt.maxInputTokens}
+			output, err := m.Summarize(context.Background(), "report", doc)
+			require.NoError(t, err)
+			assert.Equal(t, "summary of # Activity 2025-06-16", output)
+
+			stages := []string{}
+			for _, c := range fake.calls {
+				stage := c.system
+				if strings.HasPrefix(stage, "You summarize one piece") {
+					stage = "map"
+				}
+				stages = append(stages, stage)
+			}
+			assert.Equal(t, tt.stages, stages)
+		})
+	}
+
+	fake := &fakeSummarizer{}
+	m := MapReduce{Summarizer: fake, MaxInputTokens: 200}
+	_, err := m.Summarize(context.Background(), "report", doc)
+	require.NoError(t, err)
+	reduce := fake.calls[len(fake.calls)-1].input
+	assert.Equal(t, "# Activity 2025-06-16\n\nsummary of ## DX-1\n\nsummary of ## DX-2\n\nsummary of ## DX-3\n", reduce)
+
+	// a long header leaves too little room for the partial summaries
+	long := &Document{Text: doc.Text, Header: "# Activity\n" + strings.Repeat("h", 730), Parts: parts}
+	fake = &fakeSummarizer{}
+	m.Summarizer = fake
+	_, err = m.Summarize(context.Background(), "report", long)
+	require.NoError(t, err)
+	require.Len(t, fake.calls, 5)
+	assert.True(t, strings.HasPrefix(fake.calls[3].system, "You merge partial summaries"))
+	assert.Equal(t, "summary of ## DX-1\n\nsummary of ## DX-2\n\nsummary of ## DX-3\n", fake.calls[3].input)
+	assert.True(t, strings.HasSuffix(fake.calls[4].input, "\n\nsummary of summary of ## DX-1\n"))
+}
+
+func TestMapReduceSchema(t *testing.T) {
+	parts := []string{"## DX-1\n" + strings.Repeat("a", 400), "## DX-2\n" + strings.Repeat("b", 400)}
+	doc := &Document{Text: strings.Join(parts, ""), Parts: parts}
+	schema := `{"type": "object"}`
+
+	fake := &fakeStructuredSummarizer{}
+	m := MapReduce{Summarizer: fake, MaxInputTokens: 200, Schema: schema}
+	_, err := m.Summarize(context.Background(), "report", doc)
+	require.NoError(t, err)
+	require.Len(t, fake.calls, 3)
+	assert.Empty(t, fake.calls[0].schema)
+	assert.Empty(t, fake.calls[1].schema)
+	assert.Equal(t, schema, fake.calls[2].schema)
+
+	var body map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"choices": [{"index": 0, "message": {"role": "assistant", "content": "{}"}}]}`)
+	}))
+	defer server.Close()
+
+	summarizer, err := New(config.LLM{Provider: ProviderOpenAICompatible, BaseURL: server.URL + "/v1/", Model: "llama3.1"})
+	require.NoError(t, err)
+	m = MapReduce{Summarizer: summarizer, Schema: schema}
+	_, err = m.Summarize(context.Background(), "report", &Document{Text: "activity"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"type":        "json_schema",
+		"json_schema": map[string]any{"name": "entry", "schema": map[string]any{"type": "object"}},
+	}, body["response_format"])
+}
+
+func TestMapReduceCache(t *testing.T) {
+	doc := &Document{Text: "activity"}
+	fake := &fakeSummarizer{}
+	dir := filepath.Join(t.TempDir(), "llm")
+	m := MapReduce{Summarizer: fake, Model: "openai/gpt", Cache: &Cache{Dir: dir}}
+
+	for range 2 {
+		output, err := m.Summarize(context.Background(), "report", doc)
+		require.NoError(t, err)
+		assert.Equal(t, "summary of activity", output)
+	}
+	assert.Len(t, fake.calls, 1)
+
+	info, err := os.Stat(dir)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	info, err = entries[0].Info()
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	m.Model = "anthropic/claude"
+	_, err = m.Summarize(context.Background(), "report", doc)
+	require.NoError(t, err)
+	assert.Len(t, fake.calls, 2)
+}
+
+func TestMapReduceValidate(t *testing.T) {
+	validate := func(output string) error {
+		if !strings.HasPrefix(output, "{") {
+			return fmt.Errorf("not JSON")
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name    string
+		answers []string
+		retries int
+		calls   int
+		wantErr bool
+	}{
+		{name: "valid", answers: []string{"{}"}, retries: 2, calls: 1},
+		{name: "valid after retry", answers: []string{"bullets", "{}"}, retries: 2, calls: 2},
+		{name: "retries used up", answers: []string{"bullets", "still bullets"}, retries: 1, calls: 2, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeSummarizer{answers: tt.answers}
+			m := MapReduce{Summarizer: fake, Validate: validate, Retries: tt.retries, Cache: &Cache{Dir: t.TempDir()}}
+			output, err := m.Summarize(context.Background(), "report", &Document{Text: "activity"})
+			assert.Len(t, fake.calls, tt.calls)
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "not JSON")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "{}", output)
+			if tt.calls > 1 {
+				assert.Equal(t, "activity\n## Your previous answer\nbullets\n\n## Problems of the previous answer\nnot JSON\n\nAnswer again and fix the problems.\n", fake.calls[1].input)
+			}
+		})
+	}
+
+	// invalid answers are not cached
+	dir := t.TempDir()
+	fake := &fakeSummarizer{answers: []string{"bullets"}}
+	m := MapReduce{Summarizer: fake, Validate: validate, Cache: &Cache{Dir: dir}}
+	_, err := m.Summarize(context.Background(), "report", &Document{Text: "activity"})
+	assert.Error(t, err)
+	fake.answers = []string{"{}"}
+	output, err := m.Summarize(context.Background(), "report", &Document{Text: "activity"})
+	require.NoError(t, err)
+	assert.Equal(t, "{}", output)
+}
+
+func TestBatch(t *testing.T) {
+	texts := []string{strings.Repeat("a", 40), strings.Repeat("b", 40), strings.Repeat("c", 80)}
+	assert.Equal(t, [][]string{texts[:2], texts[2:]}, batch(texts, 20))
+	assert.Equal(t, [][]string{texts}, batch(texts, 100))
+}
+
+func TestCountTokens(t *testing.T) {
+	assert.Equal(t, 0, CountTokens(""))
+	assert.Equal(t, 1, CountTokens("abcd"))
+	assert.Equal(t, 2, CountTokens("äbcde"))
+}
@@ -0,0 +1,275 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultMaxInputTokens is the budget of one request. It leaves room for
+	// the answer in the context of small local models.
+	DefaultMaxInputTokens = 24000
+	// DefaultMaxSummaryTokens is the length asked of every partial summary.
+	DefaultMaxSummaryTokens = 300
+)
+
+const mapPrompt = `You summarize one piece of a software engineer's activity (a Jira ticket with its pull requests, a Jira discussion, local commits or a code review) for their daily work log.
+Write at most %d tokens of short factual bullets about what was done, decided or is blocked.
+Keep ticket keys, pull request numbers, repository names and URLs exactly as given; never invent any.
+Keep the section and epic or parent headings of the input above your bullets.`
+
+const combinePrompt = `You merge partial summaries of a software engineer's activity into one shorter list for their daily work log.
+Write at most %d tokens. Merge bullets about the same ticket, epic or pull request; keep the headings they belong to.
+Keep ticket keys, pull request numbers, repository names and URLs exactly as given; never invent any.`
+
+// CountTokens estimates the tokens of text. The tokenizers of current models
+// average about four characters per token for English prose and code, which
+// is close enough for budgeting.
+func CountTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// Document is the input to summarize: Text when it fits into one request, the
+// Header and the independently summarized Parts otherwise.
+type Document struct {
+	Text   string
+	Header string
+	Parts  []string
+}
+
+// MapReduce summarizes input that exceeds the budget of one request in
+// stages: every part is summarized on its own (map), the partial summaries
+// are merged in batches until they fit (combine), and the header with the
+// merged summaries is summarized with the system prompt (reduce).
+type MapReduce struct {
+	Summarizer Summarizer
+	// Model identifies the model in the cache keys
+	Model string
+	// Cache keeps the output of every stage; nil disables caching
+	Cache            *Cache
+	MaxInputTokens   int
+	MaxSummaryTokens int
+	// Schema is the JSON schema of the answer of the final stage. It is
+	// passed on to summarizers supporting structured output.
+	Schema string
+	// Validate checks the answer of the final stage; an invalid answer is
+	// asked for again with its problems, at most Retries times
+	Validate func(output string) error
+	Retries  int
+}
+
+// invalidAnswerError is an answer that failed the validation.
+type invalidAnswerError struct {
+	answer string
+	err    error
+}
+
+func (e *invalidAnswerError) Error() string {
+	return fmt.Sprintf("invalid answer: %s", e.err)
+}
+
+func (e *invalidAnswerError) Unwrap() error {
+	return e.err
+}
+
+// Summarize writes the report from the document, in one request if its text
+// fits the budget.
+func (m *MapReduce) Summarize(ctx context.Context, system string, doc *Document) (string, error) {
+	maxInput := m.MaxInputTokens
+	if maxInput <= 0 {
+		maxInput = DefaultMaxInputTokens
+	}
+	maxSummary := m.MaxSummaryTokens
+	if maxSummary <= 0 {
+		maxSummary = DefaultMaxSummaryTokens
+	}
+
+	tokens := CountTokens(system) + CountTokens(doc.Text)
+	if tokens <= maxInput {
+		slog.Debug("summarizing in one request", slog.Int("tokens", tokens))
+		return m.final(ctx, "single", system, doc.Text)
+	}
+	slog.Info("input exceeds the token budget, summarizing per part",
+		slog.Int("tokens", tokens), slog.Int("budget", maxInput), slog.Int("parts", len(doc.Parts)))
+
+	prompt := fmt.Sprintf(mapPrompt, maxSummary)
+	summaries := []string{}
+	for _, part := range doc.Parts {
+		summary, err := m.complete(ctx, "map", prompt, truncateTokens(part, maxInput-CountTokens(prompt)), "", nil)
+		if err != nil {
+			return "", err
+		}
+		summaries = append(summaries, summary)
+	}
+
+	budget := maxInput - CountTokens(system) - CountTokens(doc.Header)
+	prompt = fmt.Sprintf(combinePrompt, maxSummary)
+	for CountTokens(join("", summaries)) > budget {
+		batches := batch(summaries, maxInput-CountTokens(prompt))
+		if len(batches) == len(summaries) {
+			// every summary fills a request of its own, merging would not shrink them
+			break
+		}
+
+		slog.Debug("combining partial summaries", slog.Int("summaries", len(summaries)), slog.Int("batches", len(batches)))
+		combined := []string{}
+		for _, b := range batches {
+			summary, err := m.complete(ctx, "combine", prompt, join("", b), "", nil)
+			if err != nil {
+				return "", err
+			}
+			combined = append(combined, summary)
+		}
+		summaries = combined
+	}
+
+	return m.final(ctx, "reduce", system, truncateTokens(join(doc.Header, summaries), maxInput-CountTokens(system)))
+}
+
+// final completes the last stage, asking again while the answer is invalid.
+func (m *MapReduce) final(ctx context.Context, stage, system, input string) (string, error) {
+	prompt := input
+	for attempt := 0; ; attempt++ {
+		output, err := m.complete(ctx, stage, system, prompt, m.Schema, m.Validate)
+		var invalid *invalidAnswerError
+		if !errors.As(err, &invalid) || attempt >= m.Retries {
+			return output, err
+		}
+
+		slog.Warn("asking again after an invalid answer", slog.Int("attempt", attempt+1), slog.String("error", invalid.err.Error()))
+		prompt = fmt.Sprintf("%s\n## Your previous answer\n%s\n\n## Problems of the previous answer\n%s\n\nAnswer again and fix the problems.\n",
+			input, strings.TrimSpace(invalid.answer), invalid.err)
+	}
+}
+
+// complete answers the input of a stage from the cache or the model, in JSON
+// of the schema if one is given and the summarizer supports it. Only answers
+// passing validate are cached.
+func (m *MapReduce) complete(ctx context.Context, stage, system, input, schema string, validate func(string) error) (string, error) {
+	key := cacheKey(stage, m.Model, system, input)
+	if m.Cache != nil {
+		if output, ok := m.Cache.Get(key); ok && (validate == nil || validate(output) == nil) {
+			slog.Debug("using cached summary", slog.String("stage", stage), slog.String("key", key))
+			return output, nil
+		}
+	}
+
+	var output string
+	var err error
+	if structured, ok := m.Summarizer.(StructuredSummarizer); ok && schema != "" {
+		output, err = structured.SummarizeJSON(ctx, system, input, schema)
+	} else {
+		output, err = m.Summarizer.Summarize(ctx, system, input)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to summarize (%s stage): %w", stage, err)
+	}
+	if validate != nil {
+		if err := validate(output); err != nil {
+			return output, &invalidAnswerError{answer: output, err: err}
+		}
+	}
+
+	if m.Cache != nil {
+		if err := m.Cache.Put(key, output); err != nil {
+			return "", err
+		}
+	}
+	return output, nil
+}
+
+// batch packs the texts in order into batches of at most budget tokens.
+func batch(texts []string, budget int) [][]string {
+	batches := [][]string{}
+	current := []string{}
+	tokens := 0
+	for _, text := range texts {
+		t := CountTokens(text)
+		if len(current) > 0 && tokens+t > budget {
+			batches = append(batches, current)
+			current, tokens = []string{}, 0
+		}
+		current = append(current, text)
+		tokens += t
+	}
+	if len(current) > 0 {
+		batches = append(batches, current)
+	}
+	return batches
+}
+
+func join(header string, parts []string) string {
+	texts := []string{}
+	if header != "" {
+		texts = append(texts, strings.TrimRight(header, "\n"))
+	}
+	for _, part := range parts {
+		texts = append(texts, strings.TrimRight(part, "\n"))
+	}
+	return strings.Join(texts, "\n\n") + "\n"
+}
+
+// truncateTokens cuts text to about tokens tokens so that a single oversized
+// part still fits into a request.
+func truncateTokens(text string, tokens int) string {
+	if tokens <= 0 || CountTokens(text) <= tokens {
+		return text
+	}
+	runes := []rune(text)
+	return string(runes[:tokens*4]) + "\n… truncated\n"
+}
+
+func cacheKey(stage, model, system, input string) string {
+	hash := sha256.New()
+	for _, s := range []string{stage, model, system, input} {
+		hash.Write([]byte(s))
+		hash.Write([]byte{0})
+	}
+	return stage + "-" + hex.EncodeToString(hash.Sum(nil))
+}
+
+// Cache stores stage outputs as files named by their key, readable by the
+// user only since they summarize work in private repositories and tickets.
+// Entries do not expire; deleting the directory clears the cache.
+type Cache struct {
+	Dir string
+}
+
+// DefaultCacheDir is the perf directory in the user cache directory of the OS.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the user cache directory: %w", err)
+	}
+	return filepath.Join(dir, "perf", "llm"), nil
+}
+
+func (c *Cache) Get(key string) (string, bool) {
+	data, err := os.ReadFile(filepath.Join(c.Dir, key))
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			slog.Warn("failed to read cached summary", slog.String("key", key), slog.String("error", err.Error()))
+		}
+		return "", false
+	}
+	return string(data), true
+}
+
+func (c *Cache) Put(key, output string) error {
+	if err := os.MkdirAll(c.Dir, 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory %s: %w", c.Dir, err)
+	}
+	if err := os.WriteFile(filepath.Join(c.Dir, key), []byte(output), 0600); err != nil {
+		return fmt.Errorf("failed to cache summary %s: %w", key, err)
+	}
+	return nil
+}
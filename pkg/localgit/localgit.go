@@ -0,0 +1,244 @@
+package localgit
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"perf/pkg/gh"
+	"strings"
+	"time"
+)
+
+const (
+	fieldSep  = "\x1f"
+	recordSep = "\x1e"
+	logFormat = "%H" + fieldSep + "%an" + fieldSep + "%aI" + fieldSep + "%B" + recordSep
+)
+
+// FindRepositories resolves the configured paths to git working trees. A path
+// is either a clone itself or a directory whose immediate children are clones.
+func FindRepositories(paths []string) ([]string, error) {
+	repos := []string{}
+	for _, path := range paths {
+		path = expandHome(path)
+		if isRepository(path) {
+			repos = append(repos, path)
+			continue
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read directory %s: %w", path, err)
+		}
+		for _, entry := range entries {
+			child := filepath.Join(path, entry.Name())
+			if entry.IsDir() && isRepository(child) {
+				repos = append(repos, child)
+			}
+		}
+	}
+	return repos, nil
+}
+
+func isRepository(path string) bool {
+	_, err := os.Stat(filepath.Join(path, ".git"))
+	return err == nil
+}
+
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
+
+func git(repo string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s in %s failed: %w: %s", strings.Join(args, " "), repo, err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+// GetCommits returns the commits of the author on any local branch of the
+// repository between the two dates (both inclusive). When author is empty the
+// user.email configured for the repository is used.
+func GetCommits(repo, author, from, to string) ([]*gh.Commit, error) {
+	if author == "" {
+		email, err := git(repo, "config", "user.email")
+		if err != nil {
+			return nil, err
+		}
+		author = strings.TrimSpace(email)
+	}
+
+	out, err := git(repo, "log", "--branches", "--no-merges",
+		"--author="+author,
+		"--since="+from+" 00:00:00",
+		"--until="+to+" 23:59:59",
+		"--format="+logFormat,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	commits, err := parseLog(out)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse git log of %s: %w", repo, err)
+	}
+
+	name := filepath.Base(repo)
+	for _, commit := range commits {
+		commit.Repo = name
+
+		diff, err := git(repo, "show", "--format=", "--patch", "--find-renames", commit.SHA)
+		if err != nil {
+			return nil, err
+		}
+		commit.Files = parseDiff(diff)
+
+		branches, err := git(repo, "branch", "--contains", commit.SHA, "--format=%(refname:short)")
+		if err != nil {
+			return nil, err
+		}
+		commit.Branch, commit.Ticket = pickBranch(strings.Fields(branches), commit.Message)
+	}
+	return commits, nil
+}
+
+// Scan collects the commits of the author from all repositories.
+func Scan(paths []string, author, from, to string) ([]*gh.Commit, error) {
+	repos, err := FindRepositories(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	commits := []*gh.Commit{}
+	for _, repo := range repos {
+		repoCommits, err := GetCommits(repo, author, from, to)
+		if err != nil {
+			return nil, err
+		}
+		commits = append(commits, repoCommits...)
+	}
+	return commits, nil
+}
+
+// GroupByTicket groups commits by their ticket. Commits without a ticket are
+// grouped under the empty key.
+func GroupByTicket(commits []*gh.Commit) map[string][]*gh.Commit {
+	groups := map[string][]*gh.Commit{}
+	for _, commit := range commits {
+		groups[commit.Ticket] = append(groups[commit.Ticket], commit)
+	}
+	return groups
+}
+
+// pickBranch chooses the branch a commit is reported under and its ticket.
+// A branch carrying a ticket key wins; otherwise the ticket is taken from the
+// commit message. Keys are matched in upper case only, as lower-case words
+// like "fix-123" in branch names are rarely ticket keys.
+func pickBranch(branches []string, message string) (string, string) {
+	for _, branch := range branches {
+		if ticket := gh.FindTicket(branch); ticket != "" {
+			return branch, ticket
+		}
+	}
+
+	branch := ""
+	if len(branches) > 0 {
+		branch = branches[0]
+	}
+	return branch, gh.FindTicket(message)
+}
+
+func parseLog(out string) ([]*gh.Commit, error) {
+	commits := []*gh.Commit{}
+	for _, record := range strings.Split(out, recordSep) {
+		record = strings.TrimLeft(record, "\n")
+		if record == "" {
+			continue
+		}
+
+		fields := strings.SplitN(record, fieldSep, 4)
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("malformed log record %q", record)
+		}
+		timestamp, err := time.Parse(time.RFC3339, fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid author date of commit %s: %w", fields[0], err)
+		}
+
+		commits = append(commits, &gh.Commit{
+			SHA:       fields[0],
+			Author:    fields[1],
+			Timestamp: timestamp.UTC(),
+			Message:   strings.TrimSpace(fields[3]),
+		})
+	}
+	return commits, nil
+}
+
+// parseDiff splits the output of `git show --patch` into one CommitFile per file.
+func parseDiff(diff string) []*gh.CommitFile {
+	files := []*gh.CommitFile{}
+	var current *gh.CommitFile
+	var patch strings.Builder
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		current.Patch = strings.TrimSuffix(patch.String(), "\n")
+		files = append(files, current)
+		patch.Reset()
+	}
+
+	inPatch := false
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+			current = &gh.CommitFile{Status: "modified", Filename: diffTarget(line)}
+			inPatch = false
+		case current == nil:
+			continue
+		case inPatch:
+			patch.WriteString(line)
+			patch.WriteString("\n")
+		case strings.HasPrefix(line, "@@"):
+			inPatch = true
+			patch.WriteString(line)
+			patch.WriteString("\n")
+		case strings.HasPrefix(line, "new file mode"):
+			current.Status = "added"
+		case strings.HasPrefix(line, "deleted file mode"):
+			current.Status = "removed"
+		case strings.HasPrefix(line, "rename from "):
+			current.Status = "renamed"
+			current.PreviousFilename = strings.TrimPrefix(line, "rename from ")
+		case strings.HasPrefix(line, "rename to "):
+			current.Filename = strings.TrimPrefix(line, "rename to ")
+		}
+	}
+	flush()
+	return files
+}
+
+// diffTarget extracts the new file name from a "diff --git a/x b/x" header.
+func diffTarget(header string) string {
+	idx := strings.LastIndex(header, " b/")
+	if idx < 0 {
+		return ""
+	}
+	return header[idx+len(" b/"):]
+}
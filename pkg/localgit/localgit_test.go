@@ -0,0 +1,114 @@
+package localgit
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDiff(t *testing.T) {
+	diff := `diff --git a/main.go b/main.go
+index 3b18e51..a042389 100644
+--- a/main.go
++++ b/main.go
+@@ -1,3 +1,4 @@
+ package main
++
++import "fmt"
+diff --git a/old.txt b/new.txt
+similarity index 100%
+rename from old.txt
+rename to new.txt
+diff --git a/gone.txt b/gone.txt
+deleted file mode 100644
+index 3b18e51..0000000
+--- a/gone.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-bye
+`
+	files := parseDiff(diff)
+	assert.Len(t, files, 3)
+
+	assert.Equal(t, "main.go", files[0].Filename)
+	assert.Equal(t, "modified", files[0].Status)
+	assert.Equal(t, "@@ -1,3 +1,4 @@\n package main\n+\n+import \"fmt\"", files[0].Patch)
+
+	assert.Equal(t, "new.txt", files[1].Filename)
+	assert.Equal(t, "old.txt", files[1].PreviousFilename)
+	assert.Equal(t, "renamed", files[1].Status)
+	assert.Empty(t, files[1].Patch)
+
+	assert.Equal(t, "gone.txt", files[2].Filename)
+	assert.Equal(t, "removed", files[2].Status)
+}
+
+func TestPickBranch(t *testing.T) {
+	tests := []struct {
+		branches []string
+		message  string
+		branch   string
+		ticket   string
+	}{
+		{branches: []string{"main", "feat/DX-408-nix-flake"}, message: "add flake", branch: "feat/DX-408-nix-flake", ticket: "DX-408"},
+		{branches: []string{"fix-123-foo"}, message: "fix flaky test", branch: "fix-123-foo", ticket: ""},
+		{branches: []string{"feat/dx-408-nix-flake"}, message: "DX-408: add flake", branch: "feat/dx-408-nix-flake", ticket: "DX-408"},
+		{branches: []string{"main"}, message: "[PF-1647] validate schemas", branch: "main", ticket: "PF-1647"},
+		{branches: []string{}, message: "wip", branch: "", ticket: ""},
+	}
+	for _, tt := range tests {
+		branch, ticket := pickBranch(tt.branches, tt.message)
+		assert.Equal(t, tt.branch, branch)
+		assert.Equal(t, tt.ticket, ticket)
+	}
+}
+
+func TestScan(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	// git reads --since and --until in the local time zone; pin it so the
+	// commit below falls on 2025-06-16 wherever the tests run.
+	t.Setenv("TZ", "UTC")
+
+	root := t.TempDir()
+	repo := filepath.Join(root, "dunebot")
+	run := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_DATE=2025-06-16T10:00:00+02:00",
+			"GIT_COMMITTER_DATE=2025-06-16T10:00:00+02:00",
+		)
+		out, err := cmd.CombinedOutput()
+		assert.NoError(t, err, string(out))
+	}
+
+	assert.NoError(t, os.MkdirAll(repo, 0755))
+	run("init", "--initial-branch=main")
+	run("config", "user.email", "me@example.com")
+	run("config", "user.name", "Me")
+	run("checkout", "-b", "DX-75-sticky-comments")
+	assert.NoError(t, os.WriteFile(filepath.Join(repo, "comment.go"), []byte("package main\n"), 0644))
+	run("add", "comment.go")
+	run("commit", "-m", "use sticky comments")
+
+	commits, err := Scan([]string{root}, "", "2025-06-16", "2025-06-16")
+	assert.NoError(t, err)
+	assert.Len(t, commits, 1)
+
+	commit := commits[0]
+	assert.Equal(t, "use sticky comments", commit.Message)
+	assert.Equal(t, "dunebot", commit.Repo)
+	assert.Equal(t, "DX-75-sticky-comments", commit.Branch)
+	assert.Equal(t, "DX-75", commit.Ticket)
+	assert.Len(t, commit.Files, 1)
+	assert.Equal(t, "added", commit.Files[0].Status)
+
+	commits, err = Scan([]string{repo}, "", "2025-06-17", "2025-06-18")
+	assert.NoError(t, err)
+	assert.Empty(t, commits)
+}
@@ -0,0 +1,211 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"perf/pkg/gh"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v72/github"
+)
+
+// PullRequestMetrics holds the cycle-time numbers of a single authored pull request.
+type PullRequestMetrics struct {
+	PullRequest       *gh.PullRequest
+	FirstReviewAt     time.Time
+	TimeToFirstReview time.Duration
+	TimeToMerge       time.Duration
+	ReviewRounds      int
+}
+
+// ReviewLatency is the time between a review request addressed to the user and
+// the user's next review on the same pull request.
+type ReviewLatency struct {
+	PullRequest *gh.PullRequest
+	RequestedAt time.Time
+	ReviewedAt  time.Time
+	Latency     time.Duration
+}
+
+type Summary struct {
+	From                    string
+	To                      string
+	Authored                int
+	Merged                  int
+	Reviewed                int
+	MedianTimeToFirstReview time.Duration
+	MedianTimeToMerge       time.Duration
+	AvgReviewRounds         float64
+	MedianReviewLatency     time.Duration
+}
+
+// ForPullRequest computes the metrics of a pull request from its timeline.
+// Reviews by the pull request author (e.g. replies to review threads) are ignored.
+func ForPullRequest(pr *gh.PullRequest) *PullRequestMetrics {
+	m := PullRequestMetrics{PullRequest: pr}
+
+	if !pr.MergedAt.IsZero() {
+		m.TimeToMerge = pr.MergedAt.Sub(pr.CreatedAt)
+	}
+
+	// a round is a batch of reviews not interrupted by new commits
+	inRound := false
+	for _, e := range sortedTimeline(pr) {
+		switch e.Event {
+		case "committed":
+			inRound = false
+		case "reviewed":
+			if e.Actor == pr.Author {
+				continue
+			}
+			if m.FirstReviewAt.IsZero() {
+				m.FirstReviewAt = e.CreatedAt
+				m.TimeToFirstReview = e.CreatedAt.Sub(pr.CreatedAt)
+			}
+			if !inRound {
+				m.ReviewRounds++
+				inRound = true
+			}
+		}
+	}
+	return &m
+}
+
+// ReviewLatencies returns the response latency of the user for every review
+// request on the given pull requests that the user has answered with a review.
+func ReviewLatencies(prs []*gh.PullRequest, user string) []*ReviewLatency {
+	latencies := []*ReviewLatency{}
+	for _, pr := range prs {
+		var requestedAt time.Time
+		for _, e := range sortedTimeline(pr) {
+			switch {
+			case e.Event == "review_requested" && e.Reviewer == user:
+				if requestedAt.IsZero() {
+					requestedAt = e.CreatedAt
+				}
+			case e.Event == "reviewed" && e.Actor == user && !requestedAt.IsZero():
+				latencies = append(latencies, &ReviewLatency{
+					PullRequest: pr,
+					RequestedAt: requestedAt,
+					ReviewedAt:  e.CreatedAt,
+					Latency:     e.CreatedAt.Sub(requestedAt),
+				})
+				requestedAt = time.Time{}
+			}
+		}
+	}
+	return latencies
+}
+
+func Summarize(from, to string, authored []*PullRequestMetrics, latencies []*ReviewLatency) *Summary {
+	s := Summary{From: from, To: to, Authored: len(authored)}
+
+	firstReviews := []time.Duration{}
+	merges := []time.Duration{}
+	rounds := 0
+	for _, m := range authored {
+		if !m.FirstReviewAt.IsZero() {
+			s.Reviewed++
+			firstReviews = append(firstReviews, m.TimeToFirstReview)
+		}
+		if !m.PullRequest.MergedAt.IsZero() {
+			s.Merged++
+			merges = append(merges, m.TimeToMerge)
+		}
+		rounds += m.ReviewRounds
+	}
+	if s.Reviewed > 0 {
+		s.AvgReviewRounds = float64(rounds) / float64(s.Reviewed)
+	}
+
+	responses := []time.Duration{}
+	for _, l := range latencies {
+		responses = append(responses, l.Latency)
+	}
+
+	s.MedianTimeToFirstReview = median(firstReviews)
+	s.MedianTimeToMerge = median(merges)
+	s.MedianReviewLatency = median(responses)
+	return &s
+}
+
+func (s *Summary) String() string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("PR metrics %s..%s\n", s.From, s.To))
+	builder.WriteString(fmt.Sprintf("- Authored PRs: %d (merged: %d, reviewed: %d)\n", s.Authored, s.Merged, s.Reviewed))
+	builder.WriteString(fmt.Sprintf("- Median time to first review: %s\n", formatDuration(s.MedianTimeToFirstReview)))
+	builder.WriteString(fmt.Sprintf("- Median time to merge: %s\n", formatDuration(s.MedianTimeToMerge)))
+	builder.WriteString(fmt.Sprintf("- Average review rounds: %.1f\n", s.AvgReviewRounds))
+	builder.WriteString(fmt.Sprintf("- Median review response latency: %s\n", formatDuration(s.MedianReviewLatency)))
+	return builder.String()
+}
+
+func sortedTimeline(pr *gh.PullRequest) []*gh.TimelineEvent {
+	events := make([]*gh.TimelineEvent, len(pr.Timeline))
+	copy(events, pr.Timeline)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreatedAt.Before(events[j].CreatedAt)
+	})
+	return events
+}
+
+func median(ds []time.Duration) time.Duration {
+	if len(ds) == 0 {
+		return 0
+	}
+	sorted := make([]time.Duration, len(ds))
+	copy(sorted, ds)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return "n/a"
+	}
+	return d.Round(time.Minute).String()
+}
+
+// Collect fetches the pull requests the user authored and reviewed within the
+// date range together with their timelines and computes the summary.
+func Collect(client *github.Client, ctx context.Context, orgs []string, user, from, to string) (*Summary, error) {
+	authoredQuery := gh.Query{
+		Name:  "created",
+		Query: fmt.Sprintf("%s type:pr author:%s created:%s..%s", gh.OrgQualifier(orgs), user, from, to),
+	}
+	reviewedQuery := gh.Query{
+		Name:  "reviewed",
+		Query: fmt.Sprintf("%s type:pr -author:%s reviewed-by:%s updated:%s..%s", gh.OrgQualifier(orgs), user, user, from, to),
+	}
+
+	authoredPRs, err := gh.SearchPullRequests(client, ctx, authoredQuery)
+	if err != nil {
+		return nil, err
+	}
+	reviewedPRs, err := gh.SearchPullRequests(client, ctx, reviewedQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	authored := []*PullRequestMetrics{}
+	for _, pr := range authoredPRs {
+		if err := pr.FetchTimeline(client, ctx); err != nil {
+			return nil, err
+		}
+		authored = append(authored, ForPullRequest(pr))
+	}
+	for _, pr := range reviewedPRs {
+		if err := pr.FetchTimeline(client, ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return Summarize(from, to, authored, ReviewLatencies(reviewedPRs, user)), nil
+}
@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"perf/pkg/gh"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func at(hour int) time.Time {
+	return time.Date(2025, 6, 16, hour, 0, 0, 0, time.UTC)
+}
+
+func TestForPullRequest(t *testing.T) {
+	pr := &gh.PullRequest{
+		Author:    "me",
+		CreatedAt: at(8),
+		MergedAt:  at(15),
+		Timeline: []*gh.TimelineEvent{
+			{Event: "review_requested", Reviewer: "alice", CreatedAt: at(8)},
+			{Event: "reviewed", Actor: "alice", State: "changes_requested", CreatedAt: at(10)},
+			{Event: "reviewed", Actor: "bob", State: "commented", CreatedAt: at(11)},
+			{Event: "reviewed", Actor: "me", State: "commented", CreatedAt: at(12)},
+			{Event: "committed", Actor: "me", CreatedAt: at(13)},
+			{Event: "reviewed", Actor: "alice", State: "approved", CreatedAt: at(14)},
+		},
+	}
+
+	m := ForPullRequest(pr)
+	assert.Equal(t, 2*time.Hour, m.TimeToFirstReview)
+	assert.Equal(t, 7*time.Hour, m.TimeToMerge)
+	assert.Equal(t, 2, m.ReviewRounds)
+}
+
+func TestForPullRequestWithoutReviews(t *testing.T) {
+	pr := &gh.PullRequest{Author: "me", CreatedAt: at(8)}
+
+	m := ForPullRequest(pr)
+	assert.True(t, m.FirstReviewAt.IsZero())
+	assert.Equal(t, time.Duration(0), m.TimeToMerge)
+	assert.Equal(t, 0, m.ReviewRounds)
+}
+
+func TestReviewLatencies(t *testing.T) {
+	prs := []*gh.PullRequest{
+		{
+			Author: "alice",
+			Timeline: []*gh.TimelineEvent{
+				{Event: "review_requested", Reviewer: "me", CreatedAt: at(9)},
+				{Event: "reviewed", Actor: "me", CreatedAt: at(10)},
+				{Event: "review_requested", Reviewer: "me", CreatedAt: at(12)},
+				{Event: "reviewed", Actor: "me", CreatedAt: at(15)},
+			},
+		},
+		{
+			Author: "bob",
+			Timeline: []*gh.TimelineEvent{
+				// reviewed without being requested
+				{Event: "reviewed", Actor: "me", CreatedAt: at(9)},
+				// requested from somebody else
+				{Event: "review_requested", Reviewer: "carol", CreatedAt: at(10)},
+				{Event: "reviewed", Actor: "me", CreatedAt: at(11)},
+			},
+		},
+	}
+
+	latencies := ReviewLatencies(prs, "me")
+	assert.Len(t, latencies, 2)
+	assert.Equal(t, time.Hour, latencies[0].Latency)
+	assert.Equal(t, 3*time.Hour, latencies[1].Latency)
+}
+
+func TestSummarize(t *testing.T) {
+	authored := []*PullRequestMetrics{
+		{PullRequest: &gh.PullRequest{MergedAt: at(12)}, FirstReviewAt: at(9), TimeToFirstReview: time.Hour, TimeToMerge: 4 * time.Hour, ReviewRounds: 1},
+		{PullRequest: &gh.PullRequest{MergedAt: at(12)}, FirstReviewAt: at(9), TimeToFirstReview: 3 * time.Hour, TimeToMerge: 2 * time.Hour, ReviewRounds: 2},
+		{PullRequest: &gh.PullRequest{}},
+	}
+	latencies := []*ReviewLatency{{Latency: time.Hour}, {Latency: 2 * time.Hour}, {Latency: 6 * time.Hour}}
+
+	s := Summarize("2025-06-16", "2025-06-17", authored, latencies)
+	assert.Equal(t, 3, s.Authored)
+	assert.Equal(t, 2, s.Merged)
+	assert.Equal(t, 2, s.Reviewed)
+	assert.Equal(t, 2*time.Hour, s.MedianTimeToFirstReview)
+	assert.Equal(t, 3*time.Hour, s.MedianTimeToMerge)
+	assert.Equal(t, 1.5, s.AvgReviewRounds)
+	assert.Equal(t, 2*time.Hour, s.MedianReviewLatency)
+}
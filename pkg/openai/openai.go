@@ -2,24 +2,36 @@ package openai
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 
 	"github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
+	"github.com/openai/openai-go/shared"
 )
 
+// DefaultModel is used when no model is configured.
+const DefaultModel = openai.ChatModelGPT4_1Mini // switch to nano if possible
+
 func InitClient() (*openai.Client, error) {
 	apiKey, ok := os.LookupEnv("OPENAI_API_KEY")
 	if !ok {
 		return nil, fmt.Errorf("missing OPENAI_API_KEY")
 	}
-	client := openai.NewClient(
-		option.WithAPIKey(apiKey), // defaults to os.LookupEnv("OPENAI_API_KEY")
-	)
-	return &client, nil
+	return NewClient(apiKey, ""), nil
+}
 
+// NewClient creates a client for the OpenAI API, or for any OpenAI-compatible
+// server (Ollama, llama.cpp server, vLLM) if baseURL is set.
+func NewClient(apiKey, baseURL string) *openai.Client {
+	opts := []option.RequestOption{option.WithAPIKey(apiKey)}
+	if baseURL != "" {
+		opts = append(opts, option.WithBaseURL(baseURL))
+	}
+	client := openai.NewClient(opts...)
+	return &client
 }
 
 func readFile(path string) (*string, error) {
@@ -46,27 +58,84 @@ func readFile(path string) (*string, error) {
 	return &contents, nil
 }
 
-func Complete(client *openai.Client, ctx context.Context, input *string) (*string, error) {
-	prompt, err := readFile("/Users/kristina.pianykh@goflink.com/flink/perf/pkg/openai/prompt")
-	if err != nil {
-		return nil, err
+// Summarizer completes chats with a model of the OpenAI API or of an
+// OpenAI-compatible server.
+type Summarizer struct {
+	client *openai.Client
+	model  string
+}
+
+func NewSummarizer(client *openai.Client, model string) *Summarizer {
+	if model == "" {
+		model = DefaultModel
+	}
+	return &Summarizer{client: client, model: model}
+}
+
+func (s *Summarizer) Summarize(ctx context.Context, system, input string) (string, error) {
+	return s.complete(ctx, s.params(system, input))
+}
+
+// SummarizeJSON is Summarize with the answer constrained to the JSON schema
+// through response_format. Ollama maps it to the format of its own API.
+func (s *Summarizer) SummarizeJSON(ctx context.Context, system, input, schema string) (string, error) {
+	var schemaObject map[string]any
+	if err := json.Unmarshal([]byte(schema), &schemaObject); err != nil {
+		return "", fmt.Errorf("invalid JSON schema: %w", err)
 	}
 
-	chatCompletion, err := client.Chat.Completions.New(ctx, openai.ChatCompletionNewParams{
+	params := s.params(system, input)
+	params.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{
+		OfJSONSchema: &shared.ResponseFormatJSONSchemaParam{
+			JSONSchema: shared.ResponseFormatJSONSchemaJSONSchemaParam{
+				Name:   "entry",
+				Schema: schemaObject,
+			},
+		},
+	}
+	return s.complete(ctx, params)
+}
+
+func (s *Summarizer) params(system, input string) openai.ChatCompletionNewParams {
+	return openai.ChatCompletionNewParams{
 		Messages: []openai.ChatCompletionMessageParamUnion{
-			openai.UserMessage(*input),
-			openai.SystemMessage(*prompt),
+			openai.SystemMessage(system),
+			openai.UserMessage(input),
 		},
-		Model: openai.ChatModelGPT4_1Mini, // switch to nano if possible
-	})
+		Model: s.model,
+	}
+}
+
+func (s *Summarizer) complete(ctx context.Context, params openai.ChatCompletionNewParams) (string, error) {
+	chatCompletion, err := s.client.Chat.Completions.New(ctx, params)
 	if err != nil {
-		panic(err.Error())
+		return "", fmt.Errorf("failed to complete chat with %s: %w", s.model, err)
 	}
 
 	if len(chatCompletion.Choices) == 0 {
-		return nil, fmt.Errorf("failed to complete chat with %w", err)
+		return "", fmt.Errorf("chat completion with %s returned no choices", s.model)
 	}
+	return chatCompletion.Choices[0].Message.Content, nil
+}
 
-	output := chatCompletion.Choices[0].Message.Content
+// Complete summarizes the input with the default prompt, rendered with the
+// activity of data, and the default model.
+func Complete(client *openai.Client, ctx context.Context, data *PromptData, input *string) (*string, error) {
+	if client == nil {
+		return nil, fmt.Errorf("missing OpenAI client")
+	}
+	tmpl, err := LoadPrompt("")
+	if err != nil {
+		return nil, err
+	}
+	prompt, err := RenderPrompt(tmpl, data)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := NewSummarizer(client, DefaultModel).Summarize(ctx, prompt, *input)
+	if err != nil {
+		return nil, err
+	}
 	return &output, nil
 }
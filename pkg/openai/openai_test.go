@@ -3,7 +3,12 @@ package openai
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"perf/pkg/gh"
+	"perf/pkg/jirautils"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -29,18 +34,72 @@ func TestOpenFile(t *testing.T) {
 }
 
 func TestInit(t *testing.T) {
+	if _, ok := os.LookupEnv("OPENAI_API_KEY"); !ok {
+		t.Skip("OPENAI_API_KEY is not set")
+	}
 	_, err := InitClient()
 	assert.NoError(t, err)
 }
 
 func TestChatCompletion(t *testing.T) {
+	if _, ok := os.LookupEnv("OPENAI_API_KEY"); !ok {
+		t.Skip("OPENAI_API_KEY is not set")
+	}
 	client, err := InitClient()
 	assert.NoError(t, err)
 
-	assert.NoError(t, err)
 	input := "this is a test"
-	output, err := Complete(client, context.Background(), &input)
+	data := &PromptData{Date: time.Now().Format("2006-01-02"), User: "Kristina Pianykh"}
+	output, err := Complete(client, context.Background(), data, &input)
 	assert.NoError(t, err)
 	assert.NotNil(t, output)
 	fmt.Printf("output: %s\n", *output)
 }
+
+func TestCompleteWithoutClient(t *testing.T) {
+	input := "this is a test"
+	_, err := Complete(nil, context.Background(), &PromptData{Date: "2025-06-06"}, &input)
+	assert.Error(t, err)
+}
+
+func TestPrompt(t *testing.T) {
+	data := &PromptData{
+		Date: "2025-06-06",
+		User: "Kristina Pianykh",
+		Tickets: []*jirautils.Ticket{
+			{Key: "DX-408", Title: "Setup easy and composable Developer Environments", URL: "https://goflink.atlassian.net/browse/DX-408"},
+		},
+		PullRequests: []*gh.PullRequest{
+			{Owner: "goflink", Repo: "krisss", Number: 31, Title: "Add Nix flake", HTMLURL: "https://github.com/goflink/krisss/pull/31"},
+		},
+		Reviews: []*gh.ReviewsByPullRequest{{PullRequest: &gh.PullRequest{
+			Owner: "goflink", Repo: "platform-repo-templates", Number: 748, Author: "frank-i",
+			Title: "Validate values.yaml", HTMLURL: "https://github.com/goflink/platform-repo-templates/pull/748",
+		}}},
+	}
+
+	tmpl, err := LoadPrompt("")
+	assert.NoError(t, err)
+	prompt, err := RenderPrompt(tmpl, data)
+	assert.NoError(t, err)
+	assert.Contains(t, prompt, "daily work log entry of Kristina Pianykh for 2025-06-06")
+	assert.Contains(t, prompt, `"06.Jun.2025"`)
+	// the activity is part of the input only
+	assert.NotContains(t, prompt, "DX-408")
+	assert.NotContains(t, prompt, "https://github.com/goflink/krisss/pull/31")
+	assert.NotContains(t, prompt, "Validate values.yaml")
+
+	path := filepath.Join(t.TempDir(), "prompt.tmpl")
+	assert.NoError(t, os.WriteFile(path, []byte("Log of {{.User}} on {{.Day}}, {{len .PullRequests}} PRs"), 0644))
+	tmpl, err = LoadPrompt(path)
+	assert.NoError(t, err)
+	prompt, err = RenderPrompt(tmpl, data)
+	assert.NoError(t, err)
+	assert.Equal(t, "Log of Kristina Pianykh on 06.Jun.2025, 1 PRs", prompt)
+
+	assert.NoError(t, os.WriteFile(path, []byte("{{.Unknown}}"), 0644))
+	tmpl, err = LoadPrompt(path)
+	assert.NoError(t, err)
+	_, err = RenderPrompt(tmpl, data)
+	assert.Error(t, err)
+}
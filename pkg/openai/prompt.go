@@ -0,0 +1,66 @@
+package openai
+
+import (
+	_ "embed"
+	"fmt"
+	"perf/pkg/gh"
+	"perf/pkg/jirautils"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// defaultPrompt is the system prompt used unless a prompt file is configured.
+//
+//go:embed prompt
+var defaultPrompt string
+
+// PromptData is the activity the prompt template is rendered with. The
+// default prompt leaves the tickets and pull requests to the input; they are
+// available to configured prompts.
+type PromptData struct {
+	// Date is the first day of the report (YYYY-MM-DD)
+	Date         string
+	User         string
+	Tickets      []*jirautils.Ticket
+	PullRequests []*gh.PullRequest
+	Reviews      []*gh.ReviewsByPullRequest
+}
+
+// Day formats the date as it heads a log entry, e.g. "06.Jun.2025".
+func (d *PromptData) Day() string {
+	date, err := time.Parse("2006-01-02", d.Date)
+	if err != nil {
+		return d.Date
+	}
+	return date.Format("02.Jan.2006")
+}
+
+// LoadPrompt parses the prompt template at path, or the embedded default
+// prompt if path is empty.
+func LoadPrompt(path string) (*template.Template, error) {
+	text := defaultPrompt
+	name := "prompt"
+	if path != "" {
+		contents, err := readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		text, name = *contents, path
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prompt template %s: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// RenderPrompt executes the prompt template with the activity.
+func RenderPrompt(tmpl *template.Template, data *PromptData) (string, error) {
+	var builder strings.Builder
+	if err := tmpl.Execute(&builder, data); err != nil {
+		return "", fmt.Errorf("failed to render prompt template %s: %w", tmpl.Name(), err)
+	}
+	return builder.String(), nil
+}
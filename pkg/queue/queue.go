@@ -0,0 +1,155 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"perf/pkg/gh"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v72/github"
+)
+
+// Item is a pull request waiting for a review from the user or one of the user's teams.
+type Item struct {
+	PullRequest   *gh.PullRequest
+	RequestedFrom string
+	RequestedAt   time.Time
+	Age           time.Duration
+	Breached      bool
+}
+
+// NewItem determines when the pending review was requested. The most recent
+// request addressed to the user or to one of the teams counts; when the
+// timeline has none (e.g. requested via CODEOWNERS on creation) the creation
+// time of the pull request is used.
+func NewItem(pr *gh.PullRequest, user string, teams []string, sla time.Duration, now time.Time) *Item {
+	item := Item{PullRequest: pr, RequestedFrom: user, RequestedAt: pr.CreatedAt}
+
+	for _, e := range pr.Timeline {
+		if e.Event != "review_requested" || e.CreatedAt.Before(item.RequestedAt) {
+			continue
+		}
+		switch {
+		case e.Reviewer == user:
+			item.RequestedAt = e.CreatedAt
+			item.RequestedFrom = user
+		case e.Team != "" && slices.Contains(teamSlugs(teams), e.Team):
+			item.RequestedAt = e.CreatedAt
+			item.RequestedFrom = e.Team
+		}
+	}
+
+	item.Age = now.Sub(item.RequestedAt)
+	item.Breached = item.Age > sla
+	return &item
+}
+
+// teamSlugs strips the org from "org/slug" team names.
+func teamSlugs(teams []string) []string {
+	slugs := []string{}
+	for _, team := range teams {
+		parts := strings.Split(team, "/")
+		slugs = append(slugs, parts[len(parts)-1])
+	}
+	return slugs
+}
+
+// GetUserTeams returns the teams of the authenticated user in the given orgs as "org/slug".
+func GetUserTeams(client *github.Client, ctx context.Context, orgs []string) ([]string, error) {
+	ghTeams, _, err := client.Teams.ListUserTeams(ctx, &github.ListOptions{PerPage: 100})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list teams of the current user: %w", err)
+	}
+
+	teams := []string{}
+	for _, team := range ghTeams {
+		org := team.GetOrganization().GetLogin()
+		if !slices.Contains(orgs, org) {
+			continue
+		}
+		teams = append(teams, fmt.Sprintf("%s/%s", org, team.GetSlug()))
+	}
+	return teams, nil
+}
+
+// Collect returns the open pull requests in the orgs where a review is
+// requested from the user directly or from one of the user's teams, oldest
+// request first.
+func Collect(client *github.Client, ctx context.Context, orgs []string, user string, sla time.Duration, now time.Time) ([]*Item, error) {
+	teams, err := GetUserTeams(client, ctx, orgs)
+	if err != nil {
+		return nil, err
+	}
+
+	qualifier := fmt.Sprintf("%s type:pr is:open", gh.OrgQualifier(orgs))
+	queries := []gh.Query{{Name: "review-requested", Query: fmt.Sprintf("%s user-review-requested:%s", qualifier, user)}}
+	for _, team := range teams {
+		queries = append(queries, gh.Query{Name: "review-requested", Query: fmt.Sprintf("%s team-review-requested:%s", qualifier, team)})
+	}
+
+	seen := map[int64]bool{}
+	items := []*Item{}
+	for _, q := range queries {
+		prs, err := gh.SearchPullRequests(client, ctx, q)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, pr := range prs {
+			if seen[pr.ID] {
+				continue
+			}
+			seen[pr.ID] = true
+
+			if err := pr.FetchTimeline(client, ctx); err != nil {
+				return nil, err
+			}
+			if err := pr.FetchCIStatus(client, ctx); err != nil {
+				return nil, err
+			}
+			items = append(items, NewItem(pr, user, teams, sla, now))
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Age > items[j].Age
+	})
+	return items, nil
+}
+
+// Render writes the queue as a Markdown table. Rows breaching the SLA are marked.
+func Render(w io.Writer, items []*Item, sla time.Duration) {
+	if len(items) == 0 {
+		fmt.Fprintln(w, "No pending review requests.")
+		return
+	}
+
+	breached := 0
+	fmt.Fprintln(w, "| SLA | Age | Pull Request | Author | Requested from | CI | Size |")
+	fmt.Fprintln(w, "|-----|-----|--------------|--------|----------------|----|------|")
+	for _, item := range items {
+		pr := item.PullRequest
+		marker := "ok"
+		if item.Breached {
+			marker = "**BREACHED**"
+			breached++
+		}
+		fmt.Fprintf(w, "| %s | %s | [%s/%s#%d](%s) %s | %s | %s | %s | +%d/-%d |\n",
+			marker, formatAge(item.Age), pr.Owner, pr.Repo, pr.Number, pr.HTMLURL, pr.Title,
+			pr.Author, item.RequestedFrom, pr.CIStatus, pr.Additions, pr.Deletions)
+	}
+	fmt.Fprintf(w, "\n%d pending, %d older than the SLA of %s\n", len(items), breached, formatAge(sla))
+}
+
+func formatAge(d time.Duration) string {
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	if days > 0 {
+		return fmt.Sprintf("%dd%dh", days, hours)
+	}
+	return fmt.Sprintf("%dh%dm", hours, int(d.Minutes())%60)
+}
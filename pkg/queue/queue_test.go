@@ -0,0 +1,74 @@
+package queue
+
+import (
+	"bytes"
+	"perf/pkg/gh"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewItem(t *testing.T) {
+	created := time.Date(2025, 6, 16, 8, 0, 0, 0, time.UTC)
+	now := created.Add(30 * time.Hour)
+
+	tests := []struct {
+		name          string
+		timeline      []*gh.TimelineEvent
+		requestedAt   time.Time
+		requestedFrom string
+		breached      bool
+	}{
+		{
+			name:          "no request events",
+			requestedAt:   created,
+			requestedFrom: "me",
+			breached:      true,
+		},
+		{
+			name: "direct request",
+			timeline: []*gh.TimelineEvent{
+				{Event: "review_requested", Reviewer: "me", CreatedAt: created.Add(10 * time.Hour)},
+				{Event: "review_requested", Reviewer: "someone", CreatedAt: created.Add(12 * time.Hour)},
+			},
+			requestedAt:   created.Add(10 * time.Hour),
+			requestedFrom: "me",
+			breached:      false,
+		},
+		{
+			name: "team request",
+			timeline: []*gh.TimelineEvent{
+				{Event: "review_requested", Team: "platform", CreatedAt: created.Add(2 * time.Hour)},
+				{Event: "review_requested", Team: "other", CreatedAt: created.Add(20 * time.Hour)},
+			},
+			requestedAt:   created.Add(2 * time.Hour),
+			requestedFrom: "platform",
+			breached:      true,
+		},
+	}
+	for _, tt := range tests {
+		pr := &gh.PullRequest{CreatedAt: created, Timeline: tt.timeline}
+		item := NewItem(pr, "me", []string{"goflink/platform"}, 24*time.Hour, now)
+		assert.Equal(t, tt.requestedAt, item.RequestedAt, tt.name)
+		assert.Equal(t, tt.requestedFrom, item.RequestedFrom, tt.name)
+		assert.Equal(t, tt.breached, item.Breached, tt.name)
+	}
+}
+
+func TestRender(t *testing.T) {
+	items := []*Item{
+		{
+			PullRequest:   &gh.PullRequest{Owner: "goflink", Repo: "dunebot", Number: 159, Title: "sticky comments", Author: "alice", CIStatus: "success", Additions: 10, Deletions: 2},
+			RequestedFrom: "me",
+			Age:           50 * time.Hour,
+			Breached:      true,
+		},
+	}
+
+	var buf bytes.Buffer
+	Render(&buf, items, 24*time.Hour)
+	assert.Contains(t, buf.String(), "| **BREACHED** | 2d2h | [goflink/dunebot#159]")
+	assert.Contains(t, buf.String(), "| success | +10/-2 |")
+	assert.Contains(t, buf.String(), "1 pending, 1 older than the SLA of 1d0h")
+}
@@ -0,0 +1,313 @@
+package report
+
+import (
+	"fmt"
+	"perf/pkg/config"
+	"perf/pkg/gh"
+	"perf/pkg/jirautils"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// descriptionLimit caps ticket descriptions, which often carry long
+// templates that add nothing to a daily log.
+const descriptionLimit = 600
+
+// Input is the activity a report is written from. It holds only the fields
+// the report needs, in a deterministic order.
+type Input struct {
+	From         string
+	To           string
+	People       []config.Person
+	Created      []*Ticket
+	Updates      []string
+	Groups       []*Group
+	Discussions  []*Ticket
+	LocalCommits []*TicketCommits
+	Reviews      []*Review
+	Metrics      string
+}
+
+// Group is an epic or parent ticket with the tickets worked on under it.
+type Group struct {
+	Key     string
+	Title   string
+	Epic    bool
+	Tickets []*Ticket
+}
+
+type Ticket struct {
+	Key         string
+	Title       string
+	URL         string
+	Type        string
+	Status      string
+	Priority    string
+	Labels      []string
+	Sprint      string
+	StoryPoints float64
+	Links       []string
+	// Unblocks are the tickets this ticket blocks, i.e. unblocks when done
+	Unblocks     []string
+	Description  string
+	PullRequests []*PullRequest
+	Comments     []*Comment
+	Worklogs     []string
+}
+
+type PullRequest struct {
+	Repo      string
+	Number    int
+	Title     string
+	URL       string
+	Author    string
+	State     string
+	CIStatus  string
+	Additions int
+	Deletions int
+	Links     []string
+	Commits   []*Commit
+}
+
+type Commit struct {
+	SHA     string
+	Message string
+	Files   []*File
+}
+
+type File struct {
+	Name   string
+	Status string
+	Patch  string
+}
+
+type Comment struct {
+	Author    string
+	Mine      bool
+	CreatedAt time.Time
+	Body      string
+}
+
+// TicketCommits are the local commits attributed to a ticket.
+type TicketCommits struct {
+	Ticket  string
+	Commits []*Commit
+}
+
+// Review is my feedback on someone else's pull request.
+type Review struct {
+	PullRequest *PullRequest
+	States      []string
+	Comments    []string
+}
+
+func NewTicket(t *jirautils.Ticket) *Ticket {
+	ticket := Ticket{
+		Key:         t.Key,
+		Title:       t.Title,
+		URL:         t.URL,
+		Type:        t.Type,
+		Status:      t.Status,
+		Priority:    t.Priority,
+		Labels:      t.Labels,
+		StoryPoints: t.StoryPoints,
+		Description: truncate(strings.TrimSpace(t.Body), descriptionLimit),
+	}
+	if t.Sprint != nil {
+		ticket.Sprint = t.Sprint.Name
+		if t.Sprint.Goal != "" {
+			ticket.Sprint += fmt.Sprintf(" (goal: %s)", t.Sprint.Goal)
+		}
+	}
+	unblocked := t.Unblocked()
+	for _, link := range t.Links {
+		if slices.Contains(unblocked, link) {
+			continue
+		}
+		ticket.Links = append(ticket.Links, fmt.Sprintf("%s %s", link.Relation, linkText(link)))
+	}
+	for _, link := range unblocked {
+		ticket.Unblocks = append(ticket.Unblocks, linkText(link))
+	}
+
+	prs := append([]*gh.PullRequest{}, t.PullRequests...)
+	sortPullRequests(prs)
+	for _, pr := range prs {
+		ticket.PullRequests = append(ticket.PullRequests, NewPullRequest(pr))
+	}
+
+	comments := append([]*jirautils.Comment{}, t.Comments...)
+	sort.SliceStable(comments, func(i, j int) bool { return comments[i].CreatedAt.Before(comments[j].CreatedAt) })
+	for _, c := range comments {
+		ticket.Comments = append(ticket.Comments, &Comment{Author: c.Author, Mine: c.Mine, CreatedAt: c.CreatedAt, Body: strings.TrimSpace(c.Body)})
+	}
+	for _, w := range t.Worklogs {
+		worklog := w.String()
+		if w.Comment != "" {
+			worklog += fmt.Sprintf(": %s", w.Comment)
+		}
+		ticket.Worklogs = append(ticket.Worklogs, worklog)
+	}
+	return &ticket
+}
+
+func NewTickets(tickets []*jirautils.Ticket) []*Ticket {
+	sorted := append([]*jirautils.Ticket{}, tickets...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Key < sorted[j].Key })
+
+	result := []*Ticket{}
+	for _, t := range sorted {
+		result = append(result, NewTicket(t))
+	}
+	return result
+}
+
+func NewGroups(groups []*jirautils.TicketGroup) []*Group {
+	result := []*Group{}
+	for _, g := range groups {
+		result = append(result, &Group{Key: g.Key, Title: g.Title, Epic: g.Epic, Tickets: NewTickets(g.Tickets)})
+	}
+	return result
+}
+
+// NewUpdates describes the changes of the changelogs, one sentence each.
+func NewUpdates(changelogs []*jirautils.ChangelogItem) []string {
+	updates := []string{}
+	for _, changelog := range changelogs {
+		for _, sentence := range changelog.Describe() {
+			updates = append(updates, fmt.Sprintf("%s (%s)", sentence, changelog.Ticket.Title))
+		}
+	}
+	return updates
+}
+
+func NewPullRequest(pr *gh.PullRequest) *PullRequest {
+	state := pr.Stage
+	if state == "" {
+		state = pr.State
+	}
+	if pr.Draft {
+		state = "draft"
+	}
+
+	result := PullRequest{
+		Repo:      fmt.Sprintf("%s/%s", pr.Owner, pr.Repo),
+		Number:    pr.Number,
+		Title:     pr.Title,
+		URL:       pr.HTMLURL,
+		Author:    pr.Author,
+		State:     state,
+		CIStatus:  pr.CIStatus,
+		Additions: pr.Additions,
+		Deletions: pr.Deletions,
+	}
+	for _, ref := range append(append([]*gh.IssueRef{}, pr.LinkedIssues...), pr.RelatedPullRequests...) {
+		link := fmt.Sprintf("%s/%s#%d %s", ref.Owner, ref.Repo, ref.Number, ref.Title)
+		if ref.Closes {
+			link = "closes " + link
+		}
+		result.Links = append(result.Links, link)
+	}
+
+	commits := append([]*gh.Commit{}, pr.Commits...)
+	sort.SliceStable(commits, func(i, j int) bool { return commits[i].Timestamp.Before(commits[j].Timestamp) })
+	for _, c := range commits {
+		result.Commits = append(result.Commits, NewCommit(c))
+	}
+	return &result
+}
+
+func NewCommit(c *gh.Commit) *Commit {
+	commit := Commit{SHA: shortSHA(c.SHA), Message: strings.TrimSpace(c.Message)}
+	for _, f := range c.Files {
+		commit.Files = append(commit.Files, &File{Name: f.Filename, Status: f.Status, Patch: f.Patch})
+	}
+	return &commit
+}
+
+// NewLocalCommits orders the local commits by ticket and time.
+func NewLocalCommits(commitsByTicket map[string][]*gh.Commit) []*TicketCommits {
+	keys := []string{}
+	for key := range commitsByTicket {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := []*TicketCommits{}
+	for _, key := range keys {
+		commits := append([]*gh.Commit{}, commitsByTicket[key]...)
+		sort.SliceStable(commits, func(i, j int) bool { return commits[i].Timestamp.Before(commits[j].Timestamp) })
+
+		tc := TicketCommits{Ticket: key}
+		for _, c := range commits {
+			tc.Commits = append(tc.Commits, NewCommit(c))
+		}
+		result = append(result, &tc)
+	}
+	return result
+}
+
+// NewReviews keeps the state and text of my reviews and comments, ordered by
+// repository and number.
+func NewReviews(reviewsByPR map[string]*gh.ReviewsByPullRequest) []*Review {
+	all := []*gh.ReviewsByPullRequest{}
+	for _, r := range reviewsByPR {
+		all = append(all, r)
+	}
+	sort.Slice(all, func(i, j int) bool { return pullRequestLess(all[i].PullRequest, all[j].PullRequest) })
+
+	reviews := []*Review{}
+	for _, r := range all {
+		review := Review{PullRequest: NewPullRequest(r.PullRequest)}
+		for _, ghReview := range r.Reviews {
+			review.States = append(review.States, strings.ToLower(ghReview.Summary.GetState()))
+			if body := strings.TrimSpace(ghReview.Summary.GetBody()); body != "" {
+				review.Comments = append(review.Comments, body)
+			}
+			for _, c := range ghReview.Comments {
+				review.Comments = append(review.Comments, fmt.Sprintf("%s: %s", c.GetPath(), strings.TrimSpace(c.GetBody())))
+			}
+		}
+		for _, c := range r.Comments {
+			review.Comments = append(review.Comments, strings.TrimSpace(c.GetBody()))
+		}
+		reviews = append(reviews, &review)
+	}
+	return reviews
+}
+
+func sortPullRequests(prs []*gh.PullRequest) {
+	sort.SliceStable(prs, func(i, j int) bool { return pullRequestLess(prs[i], prs[j]) })
+}
+
+func pullRequestLess(a, b *gh.PullRequest) bool {
+	if a.Owner+"/"+a.Repo != b.Owner+"/"+b.Repo {
+		return a.Owner+"/"+a.Repo < b.Owner+"/"+b.Repo
+	}
+	return a.Number < b.Number
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit]) + "…"
+}
+
+func linkText(link *jirautils.TicketLink) string {
+	text := fmt.Sprintf("%s %s", link.Key, link.Title)
+	if link.Status != "" {
+		text += fmt.Sprintf(" (%s)", link.Status)
+	}
+	return text
+}
@@ -0,0 +1,282 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Renderer writes the input as compact Markdown: one heading per section and
+// item, one bullet per non-empty field.
+type Renderer struct {
+	// PatchLines limits the diff lines rendered per changed file; 0 leaves
+	// the patches out.
+	PatchLines int
+}
+
+const (
+	peopleTitle      = "People (the same person on GitHub and Jira)"
+	createdTitle     = "Jira tickets I created"
+	updatesTitle     = "Jira ticket updates I made"
+	groupsTitle      = "Individual contributions grouped by epic or parent ticket (write one bullet per group; emphasize high-priority and incident work and mention the tickets listed as \"unblocks\")"
+	discussionsTitle = "Jira discussions I took part in (comments marked \"mine\" are mine)"
+	localTitle       = "Local commits by Jira ticket"
+	reviewsTitle     = "Pull requests I reviewed"
+	metricsTitle     = "Metrics"
+)
+
+func (r *Renderer) Render(w io.Writer, input *Input) {
+	r.renderHeader(w, input)
+
+	if len(input.Groups) > 0 {
+		section(w, groupsTitle)
+		for _, group := range input.Groups {
+			renderGroupHeading(w, group)
+			for _, ticket := range group.Tickets {
+				r.renderTicket(w, "####", ticket)
+			}
+		}
+	}
+
+	if len(input.Discussions) > 0 {
+		section(w, discussionsTitle)
+		for _, ticket := range input.Discussions {
+			r.renderTicket(w, "###", ticket)
+		}
+	}
+
+	if len(input.LocalCommits) > 0 {
+		section(w, localTitle)
+		for _, tc := range input.LocalCommits {
+			r.renderTicketCommits(w, tc)
+		}
+	}
+
+	if len(input.Reviews) > 0 {
+		section(w, reviewsTitle)
+		for _, review := range input.Reviews {
+			renderReview(w, review)
+		}
+	}
+
+	renderMetrics(w, input.Metrics)
+}
+
+// Split renders the input for summarizing it in parts. The header holds the
+// date, the people, the created tickets, the updates and the metrics; every
+// contributed ticket, discussion, ticket with local commits and review is a
+// part of its own, headed by its section.
+func (r *Renderer) Split(input *Input) (string, []string) {
+	var header strings.Builder
+	r.renderHeader(&header, input)
+	renderMetrics(&header, input.Metrics)
+
+	parts := []string{}
+	part := func(title string, render func(w io.Writer)) {
+		var b strings.Builder
+		fmt.Fprintf(&b, "## %s\n", title)
+		render(&b)
+		parts = append(parts, b.String())
+	}
+
+	for _, group := range input.Groups {
+		for _, ticket := range group.Tickets {
+			part(groupsTitle, func(w io.Writer) {
+				renderGroupHeading(w, group)
+				r.renderTicket(w, "####", ticket)
+			})
+		}
+	}
+	for _, ticket := range input.Discussions {
+		part(discussionsTitle, func(w io.Writer) { r.renderTicket(w, "###", ticket) })
+	}
+	for _, tc := range input.LocalCommits {
+		part(localTitle, func(w io.Writer) { r.renderTicketCommits(w, tc) })
+	}
+	for _, review := range input.Reviews {
+		part(reviewsTitle, func(w io.Writer) { renderReview(w, review) })
+	}
+	return header.String(), parts
+}
+
+func (r *Renderer) renderHeader(w io.Writer, input *Input) {
+	fmt.Fprintf(w, "# Activity %s", input.From)
+	if input.To != "" && input.To != input.From {
+		fmt.Fprintf(w, " to %s", input.To)
+	}
+	fmt.Fprintln(w)
+
+	// Jira account IDs mean nothing to the model, only GitHub logins need a name
+	people := []string{}
+	for _, person := range input.People {
+		if person.GitHub != "" {
+			people = append(people, fmt.Sprintf("- %s: GitHub @%s", person.Name, person.GitHub))
+		}
+	}
+	if len(people) > 0 {
+		section(w, peopleTitle)
+		for _, line := range people {
+			fmt.Fprintln(w, line)
+		}
+	}
+
+	if len(input.Created) > 0 {
+		section(w, createdTitle)
+		for _, ticket := range input.Created {
+			r.renderTicket(w, "###", ticket)
+		}
+	}
+
+	if len(input.Updates) > 0 {
+		section(w, updatesTitle)
+		for _, update := range input.Updates {
+			fmt.Fprintf(w, "- %s\n", update)
+		}
+	}
+}
+
+func renderGroupHeading(w io.Writer, group *Group) {
+	kind := "Parent"
+	if group.Epic {
+		kind = "Epic"
+	}
+	fmt.Fprintf(w, "\n### %s %s: %s\n", kind, group.Key, group.Title)
+}
+
+func (r *Renderer) renderTicketCommits(w io.Writer, tc *TicketCommits) {
+	key := tc.Ticket
+	if key == "" {
+		key = "No ticket"
+	}
+	fmt.Fprintf(w, "\n### %s\n", key)
+	for _, commit := range tc.Commits {
+		r.renderCommit(w, "", commit)
+	}
+}
+
+func renderReview(w io.Writer, review *Review) {
+	pr := review.PullRequest
+	fmt.Fprintf(w, "\n### %s#%d: %s\n", pr.Repo, pr.Number, pr.Title)
+	field(w, "", "url", pr.URL)
+	field(w, "", "author", pr.Author)
+	field(w, "", "my reviews", strings.Join(review.States, ", "))
+	for _, comment := range review.Comments {
+		text(w, "", "- comment: ", comment)
+	}
+}
+
+func renderMetrics(w io.Writer, metrics string) {
+	if metrics == "" {
+		return
+	}
+	section(w, metricsTitle)
+	fmt.Fprint(w, strings.TrimRight(metrics, "\n")+"\n")
+}
+
+func (r *Renderer) renderTicket(w io.Writer, heading string, ticket *Ticket) {
+	fmt.Fprintf(w, "\n%s %s: %s\n", heading, ticket.Key, ticket.Title)
+	field(w, "", "url", ticket.URL)
+	field(w, "", "type", ticket.Type)
+	field(w, "", "status", ticket.Status)
+	field(w, "", "priority", ticket.Priority)
+	field(w, "", "labels", strings.Join(ticket.Labels, ", "))
+	field(w, "", "sprint", ticket.Sprint)
+	if ticket.StoryPoints > 0 {
+		field(w, "", "story points", fmt.Sprintf("%g", ticket.StoryPoints))
+	}
+	for _, link := range ticket.Links {
+		field(w, "", "link", link)
+	}
+	for _, link := range ticket.Unblocks {
+		field(w, "", "unblocks", link)
+	}
+	if ticket.Description != "" {
+		text(w, "", "- description: ", ticket.Description)
+	}
+	for _, worklog := range ticket.Worklogs {
+		field(w, "", "worklog", worklog)
+	}
+	for _, comment := range ticket.Comments {
+		author := comment.Author
+		if comment.Mine {
+			author += " (mine)"
+		}
+		text(w, "", fmt.Sprintf("- comment by %s at %s: ", author, comment.CreatedAt.Format("2006-01-02 15:04")), comment.Body)
+	}
+	for _, pr := range ticket.PullRequests {
+		r.renderPullRequest(w, "", pr)
+	}
+}
+
+func (r *Renderer) renderPullRequest(w io.Writer, indent string, pr *PullRequest) {
+	details := []string{}
+	if pr.State != "" {
+		details = append(details, pr.State)
+	}
+	if pr.CIStatus != "" {
+		details = append(details, "CI "+pr.CIStatus)
+	}
+	if pr.Additions > 0 || pr.Deletions > 0 {
+		details = append(details, fmt.Sprintf("+%d -%d", pr.Additions, pr.Deletions))
+	}
+
+	fmt.Fprintf(w, "%s- pull request %s#%d: %s", indent, pr.Repo, pr.Number, pr.Title)
+	if len(details) > 0 {
+		fmt.Fprintf(w, " (%s)", strings.Join(details, ", "))
+	}
+	fmt.Fprintln(w)
+
+	indent += "  "
+	field(w, indent, "url", pr.URL)
+	for _, link := range pr.Links {
+		field(w, indent, "link", link)
+	}
+	for _, commit := range pr.Commits {
+		r.renderCommit(w, indent, commit)
+	}
+}
+
+func (r *Renderer) renderCommit(w io.Writer, indent string, commit *Commit) {
+	text(w, indent, fmt.Sprintf("- commit %s: ", commit.SHA), commit.Message)
+	indent += "  "
+	for _, file := range commit.Files {
+		fmt.Fprintf(w, "%s- %s %s\n", indent, file.Status, file.Name)
+		if r.PatchLines == 0 || file.Patch == "" {
+			continue
+		}
+		lines := strings.Split(strings.TrimRight(file.Patch, "\n"), "\n")
+		if len(lines) > r.PatchLines {
+			lines = append(lines[:r.PatchLines], fmt.Sprintf("… %d more lines", len(lines)-r.PatchLines))
+		}
+		fmt.Fprintf(w, "%s  ```diff\n", indent)
+		for _, line := range lines {
+			fmt.Fprintf(w, "%s  %s\n", indent, line)
+		}
+		fmt.Fprintf(w, "%s  ```\n", indent)
+	}
+}
+
+func section(w io.Writer, title string) {
+	fmt.Fprintf(w, "\n## %s\n", title)
+}
+
+func field(w io.Writer, indent, name, value string) {
+	if value == "" {
+		return
+	}
+	fmt.Fprintf(w, "%s- %s: %s\n", indent, name, value)
+}
+
+// text writes a possibly multi-line text after prefix, indenting the
+// continuation lines below it.
+func text(w io.Writer, indent, prefix, value string) {
+	lines := strings.Split(strings.TrimSpace(value), "\n")
+	fmt.Fprintf(w, "%s%s%s\n", indent, prefix, strings.TrimRight(lines[0], " \r"))
+	for _, line := range lines[1:] {
+		line = strings.TrimRight(line, " \r")
+		if line == "" {
+			continue
+		}
+		fmt.Fprintf(w, "%s  %s\n", indent, line)
+	}
+}
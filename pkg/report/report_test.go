@@ -0,0 +1,128 @@
+package report
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"perf/pkg/config"
+	"perf/pkg/gh"
+	"perf/pkg/jirautils"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v72/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+func assertGolden(t *testing.T, name, actual string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		require.NoError(t, os.WriteFile(path, []byte(actual), 0644))
+	}
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), actual)
+}
+
+func testInput() *Input {
+	at := time.Date(2025, 6, 16, 9, 30, 0, 0, time.UTC)
+	pr := &gh.PullRequest{
+		Owner: "goflink", Repo: "dev-envs", Number: 31, Title: "Add compose profiles",
+		HTMLURL: "https://github.com/goflink/dev-envs/pull/31", Author: "Kristina-Pianykh",
+		State: "closed", Stage: gh.StageMerged, CIStatus: gh.CIStatusSuccess, Additions: 40, Deletions: 3,
+		LinkedIssues: []*gh.IssueRef{{Owner: "goflink", Repo: "dev-envs", Number: 30, Title: "Profiles", Closes: true}},
+		Commits: []*gh.Commit{
+			{SHA: "bbbbbbbbbb", Message: "DX-408: document profiles", Timestamp: at.Add(time.Hour),
+				Files: []*gh.CommitFile{{Filename: "README.md", Status: "modified"}}},
+			{SHA: "aaaaaaaaaa", Message: "DX-408: add profiles\n\nCo-located with the services.", Timestamp: at,
+				Files: []*gh.CommitFile{{Filename: "compose.yaml", Status: "added", Patch: "@@ -0,0 +1,3 @@\n+services:\n+  db:\n+    image: postgres"}}},
+		},
+	}
+	ticket := &jirautils.Ticket{
+		Key: "DX-408", Title: "Dev envs", URL: "https://goflink.atlassian.net/browse/DX-408",
+		Type: "Story", Status: "In Progress", Priority: "High", Labels: []string{"devx"},
+		Body:        "Provide local environments.\n\nWith profiles.",
+		Sprint:      &jirautils.Sprint{Name: "DX 25", Goal: "Ship profiles"},
+		StoryPoints: 3,
+		Links: []*jirautils.TicketLink{
+			{Relation: "relates to", Key: "DX-300", Title: "Old setup", Status: "Done"},
+			{Relation: "blocks", Key: "DX-411", Title: "Profiles for CI", Status: "To Do"},
+		},
+		Epic:         &jirautils.TicketRef{Key: "DX-75", Title: "Developer experience", Type: "Epic"},
+		PullRequests: []*gh.PullRequest{pr},
+		Comments: []*jirautils.Comment{
+			{Author: "Frank Ittermann", CreatedAt: at.Add(2 * time.Hour), Body: "Looks good"},
+			{Author: "Kristina Pianykh", Mine: true, CreatedAt: at, Body: "Started with the database"},
+		},
+		Worklogs: []*jirautils.Worklog{{Ticket: "DX-408", Started: at, TimeSpent: 90 * time.Minute, Comment: "pairing"}},
+	}
+	reviews := map[string]*gh.ReviewsByPullRequest{
+		"goflink/infra/7": {
+			PullRequest: &gh.PullRequest{Owner: "goflink", Repo: "infra", Number: 7, Title: "Pin images", HTMLURL: "https://github.com/goflink/infra/pull/7", Author: "frank-i", State: "open"},
+			Reviews: []*gh.Review{{
+				Summary:  &github.PullRequestReview{State: github.Ptr("CHANGES_REQUESTED"), Body: github.Ptr("Please pin by digest")},
+				Comments: []*github.PullRequestComment{{Path: github.Ptr("main.tf"), Body: github.Ptr("use a variable")}},
+			}},
+			Comments: []*github.IssueComment{{Body: github.Ptr("Thanks!")}},
+		},
+	}
+
+	return &Input{
+		From: "2025-06-16",
+		To:   "2025-06-17",
+		People: []config.Person{
+			{Name: "Frank Ittermann", GitHub: "frank-i", JiraAccountID: "712020:0c1f"},
+			{Name: "Kristina Pianykh", GitHub: "Kristina-Pianykh"},
+		},
+		Created: NewTickets([]*jirautils.Ticket{{Key: "DX-410", Title: "Document profiles", Type: "Task", Status: "To Do"}}),
+		Updates: NewUpdates([]*jirautils.ChangelogItem{{
+			Ticket:  &jirautils.Ticket{Key: "DX-408", Title: "Dev envs"},
+			Changes: []*jirautils.Change{{Field: "status", From: "To Do", To: "In Progress"}},
+		}}),
+		Groups:       NewGroups(jirautils.GroupTickets([]*jirautils.Ticket{ticket})),
+		LocalCommits: NewLocalCommits(map[string][]*gh.Commit{"DX-408": {{SHA: "cccccccccc", Message: "DX-408: wip", Timestamp: at}}}),
+		Reviews:      NewReviews(reviews),
+		Metrics:      "PR metrics 2025-06-16..2025-06-17\n- Authored PRs: 1\n",
+	}
+}
+
+func TestRender(t *testing.T) {
+	var out bytes.Buffer
+	(&Renderer{PatchLines: 2}).Render(&out, testInput())
+	assertGolden(t, "input", out.String())
+}
+
+func TestSplit(t *testing.T) {
+	header, parts := (&Renderer{}).Split(testInput())
+	assert.Len(t, parts, 3)
+	assertGolden(t, "split", header+"\n---\n"+strings.Join(parts, "\n---\n"))
+}
+
+func TestRenderEmpty(t *testing.T) {
+	var out bytes.Buffer
+	(&Renderer{}).Render(&out, &Input{From: "2025-06-16", To: "2025-06-16"})
+	assert.Equal(t, "# Activity 2025-06-16\n", out.String())
+}
+
+func TestNewTicket(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{"short", " Fix it \n", "Fix it"},
+		{"long", strings.Repeat("é", descriptionLimit+10), strings.Repeat("é", descriptionLimit) + "…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ticket := NewTicket(&jirautils.Ticket{Key: "DX-1", Body: tt.body})
+			assert.Equal(t, tt.expected, ticket.Description)
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package stale
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"perf/pkg/gh"
+	"perf/pkg/jirautils"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/v72/github"
+)
+
+// Ticket is an in-progress or in-review ticket without recent activity.
+type Ticket struct {
+	Ticket *jirautils.Ticket
+	// LastActivity is the latest PR update or commit of the ticket, or the
+	// last Jira update if there is no linked activity at all
+	LastActivity time.Time
+	Linked       bool
+	Idle         time.Duration
+}
+
+// PullRequest is an open pull request without recent updates.
+type PullRequest struct {
+	PullRequest *gh.PullRequest
+	Idle        time.Duration
+}
+
+type Report struct {
+	Threshold    time.Duration
+	Tickets      []*Ticket
+	PullRequests []*PullRequest
+}
+
+// Detect cross-references the tickets with the activity of pull requests and
+// commits on them and keeps the tickets and open pull requests idle for longer
+// than the threshold, most idle first.
+func Detect(tickets []*jirautils.Ticket, prs []*gh.PullRequest, commits []*gh.Commit, threshold time.Duration, now time.Time) *Report {
+	report := Report{Threshold: threshold, Tickets: []*Ticket{}, PullRequests: []*PullRequest{}}
+
+	activity := map[string]time.Time{}
+	touch := func(key string, at time.Time) {
+		if key != "" && at.After(activity[key]) {
+			activity[key] = at
+		}
+	}
+	for _, pr := range prs {
+		touch(pr.Ticket, pr.UpdatedAt)
+	}
+	for _, commit := range commits {
+		touch(commit.Ticket, commit.Timestamp)
+	}
+
+	for _, ticket := range tickets {
+		last, linked := activity[ticket.Key]
+		if !linked {
+			last = ticket.Updated
+		}
+		if idle := now.Sub(last); idle > threshold {
+			report.Tickets = append(report.Tickets, &Ticket{Ticket: ticket, LastActivity: last, Linked: linked, Idle: idle})
+		}
+	}
+
+	for _, pr := range prs {
+		if pr.State != "open" {
+			continue
+		}
+		if idle := now.Sub(pr.UpdatedAt); idle > threshold {
+			report.PullRequests = append(report.PullRequests, &PullRequest{PullRequest: pr, Idle: idle})
+		}
+	}
+
+	sort.SliceStable(report.Tickets, func(i, j int) bool { return report.Tickets[i].Idle > report.Tickets[j].Idle })
+	sort.SliceStable(report.PullRequests, func(i, j int) bool { return report.PullRequests[i].Idle > report.PullRequests[j].Idle })
+	return &report
+}
+
+// GetActiveTickets returns my tickets whose status is in the "In Progress"
+// category, which covers both in-progress and in-review statuses.
+func GetActiveTickets(client *jirautils.Client) ([]*jirautils.Ticket, error) {
+	filter := jirautils.Filter{
+		Name: "Active tickets",
+		Jql:  "assignee = currentUser() AND statusCategory = \"In Progress\" ORDER BY updated ASC",
+	}
+	return jirautils.GetTicketsByFilter(client, &filter)
+}
+
+// GetPullRequests returns my open pull requests together with the ones
+// updated since the given day, which carry the recent activity on tickets.
+func GetPullRequests(client *github.Client, ctx context.Context, orgs []string, user, since string) ([]*gh.PullRequest, error) {
+	queries := []gh.Query{
+		{Name: "open", Query: fmt.Sprintf("%s type:pr is:open author:%s", gh.OrgQualifier(orgs), user)},
+		{Name: "updated", Query: fmt.Sprintf("%s type:pr is:closed author:%s updated:>=%s", gh.OrgQualifier(orgs), user, since)},
+	}
+
+	prs := []*gh.PullRequest{}
+	for _, q := range queries {
+		found, err := gh.SearchPullRequests(client, ctx, q)
+		if err != nil {
+			return nil, err
+		}
+		prs = append(prs, found...)
+	}
+	return prs, nil
+}
+
+// Render writes the stale tickets and pull requests as Markdown tables.
+func Render(w io.Writer, report *Report) {
+	days := int(report.Threshold.Hours() / 24)
+
+	fmt.Fprintf(w, "## Tickets without activity for %d days\n\n", days)
+	if len(report.Tickets) == 0 {
+		fmt.Fprintln(w, "None.")
+	} else {
+		fmt.Fprintln(w, "| Idle | Ticket | Status | Last activity |")
+		fmt.Fprintln(w, "|------|--------|--------|---------------|")
+		for _, t := range report.Tickets {
+			last := t.LastActivity.Format("2006-01-02")
+			if !t.Linked {
+				last += " (no PR or commit)"
+			}
+			fmt.Fprintf(w, "| %s | %s %s | %s | %s |\n", formatIdle(t.Idle), t.Ticket.Key, t.Ticket.Title, t.Ticket.Status, last)
+		}
+	}
+
+	fmt.Fprintf(w, "\n## Open pull requests without updates for %d days\n\n", days)
+	if len(report.PullRequests) == 0 {
+		fmt.Fprintln(w, "None.")
+		return
+	}
+	fmt.Fprintln(w, "| Idle | Pull Request | Ticket |")
+	fmt.Fprintln(w, "|------|--------------|--------|")
+	for _, p := range report.PullRequests {
+		pr := p.PullRequest
+		fmt.Fprintf(w, "| %s | [%s/%s#%d](%s) %s | %s |\n",
+			formatIdle(p.Idle), pr.Owner, pr.Repo, pr.Number, pr.HTMLURL, pr.Title, pr.Ticket)
+	}
+}
+
+func formatIdle(d time.Duration) string {
+	return fmt.Sprintf("%dd", int(d.Hours())/24)
+}
@@ -0,0 +1,73 @@
+package stale
+
+import (
+	"bytes"
+	"perf/pkg/gh"
+	"perf/pkg/jirautils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetect(t *testing.T) {
+	now := time.Date(2025, 6, 20, 12, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) time.Time { return now.AddDate(0, 0, -days) }
+
+	tickets := []*jirautils.Ticket{
+		{Key: "DX-1", Title: "Active via PR", Status: "In Review", Updated: daysAgo(30)},
+		{Key: "DX-2", Title: "Active via commit", Status: "In Progress", Updated: daysAgo(30)},
+		{Key: "DX-3", Title: "Old PR only", Status: "In Review", Updated: daysAgo(1)},
+		{Key: "DX-4", Title: "Nothing linked", Status: "In Progress", Updated: daysAgo(9)},
+		{Key: "DX-5", Title: "Just started", Status: "In Progress", Updated: daysAgo(1)},
+	}
+	prs := []*gh.PullRequest{
+		{Number: 1, Ticket: "DX-1", State: "open", UpdatedAt: daysAgo(1)},
+		{Number: 2, Ticket: "DX-3", State: "open", UpdatedAt: daysAgo(12)},
+		{Number: 3, Ticket: "DX-2", State: "closed", UpdatedAt: daysAgo(20)},
+		{Number: 4, Ticket: "", State: "open", UpdatedAt: daysAgo(6)},
+	}
+	commits := []*gh.Commit{
+		{Ticket: "DX-2", Timestamp: daysAgo(2)},
+		{Ticket: "", Timestamp: daysAgo(0)},
+	}
+
+	report := Detect(tickets, prs, commits, 5*24*time.Hour, now)
+
+	require.Len(t, report.Tickets, 2)
+	assert.Equal(t, "DX-3", report.Tickets[0].Ticket.Key)
+	assert.True(t, report.Tickets[0].Linked)
+	assert.Equal(t, daysAgo(12), report.Tickets[0].LastActivity)
+	assert.Equal(t, "DX-4", report.Tickets[1].Ticket.Key)
+	assert.False(t, report.Tickets[1].Linked)
+
+	require.Len(t, report.PullRequests, 2)
+	assert.Equal(t, 2, report.PullRequests[0].PullRequest.Number)
+	assert.Equal(t, 4, report.PullRequests[1].PullRequest.Number)
+}
+
+func TestRender(t *testing.T) {
+	report := &Report{
+		Threshold: 5 * 24 * time.Hour,
+		Tickets: []*Ticket{{
+			Ticket:       &jirautils.Ticket{Key: "DX-4", Title: "Nothing linked", Status: "In Progress"},
+			LastActivity: time.Date(2025, 6, 11, 12, 0, 0, 0, time.UTC),
+			Idle:         9 * 24 * time.Hour,
+		}},
+		PullRequests: []*PullRequest{},
+	}
+
+	var out bytes.Buffer
+	Render(&out, report)
+	assert.Equal(t, `## Tickets without activity for 5 days
+
+| Idle | Ticket | Status | Last activity |
+|------|--------|--------|---------------|
+| 9d | DX-4 Nothing linked | In Progress | 2025-06-11 (no PR or commit) |
+
+## Open pull requests without updates for 5 days
+
+None.
+`, out.String())
+}
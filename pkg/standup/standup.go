@@ -0,0 +1,137 @@
+package standup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"perf/pkg/gh"
+	"perf/pkg/jirautils"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v72/github"
+)
+
+// Standup is a daily standup message.
+type Standup struct {
+	Yesterday []string
+	Today     []string
+	Blockers  []string
+}
+
+// Activity is what happened since the last standup.
+type Activity struct {
+	PullRequests []*gh.PullRequest
+	Reviews      map[string]*gh.ReviewsByPullRequest
+	Created      []*jirautils.Ticket
+	Changelogs   []*jirautils.ChangelogItem
+}
+
+// Build assembles the standup: yesterday from the activity, today from the
+// To Do and In Progress columns of the board, blockers from the Blocked
+// column and my pull requests awaiting review.
+func Build(activity *Activity, board *jirautils.Board, awaitingReview []*gh.PullRequest) *Standup {
+	s := Standup{Yesterday: []string{}, Today: []string{}, Blockers: []string{}}
+
+	for _, pr := range activity.PullRequests {
+		verb := "Worked on"
+		switch {
+		case !pr.MergedAt.IsZero():
+			verb = "Merged"
+		case pr.Created:
+			verb = "Opened"
+		}
+		s.Yesterday = append(s.Yesterday, fmt.Sprintf("%s %s%s", verb, pullRequestLink(pr), ticketSuffix(pr.Ticket)))
+	}
+	for _, key := range sortedKeys(activity.Reviews) {
+		pr := activity.Reviews[key].PullRequest
+		s.Yesterday = append(s.Yesterday, fmt.Sprintf("Reviewed %s", pullRequestLink(pr)))
+	}
+	for _, ticket := range activity.Created {
+		s.Yesterday = append(s.Yesterday, fmt.Sprintf("Created %s %s", ticket.Key, escape(ticket.Title)))
+	}
+	for _, changelog := range activity.Changelogs {
+		for _, change := range changelog.Changes {
+			if change.Field == "status" {
+				s.Yesterday = append(s.Yesterday, fmt.Sprintf("Moved %s %s to %s",
+					changelog.Ticket.Key, escape(changelog.Ticket.Title), change.To))
+			}
+		}
+	}
+
+	if board != nil {
+		for _, column := range []jirautils.Column{jirautils.ColumnInProgress, jirautils.ColumnToDo} {
+			for _, ticket := range board.Columns[column] {
+				s.Today = append(s.Today, fmt.Sprintf("%s %s (%s)", ticket.Key, escape(ticket.Title), ticket.Status))
+			}
+		}
+		for _, ticket := range board.Columns[jirautils.ColumnBlocked] {
+			s.Blockers = append(s.Blockers, fmt.Sprintf("%s %s is blocked", ticket.Key, escape(ticket.Title)))
+		}
+	}
+	for _, pr := range awaitingReview {
+		s.Blockers = append(s.Blockers, fmt.Sprintf("%s is waiting for review", pullRequestLink(pr)))
+	}
+	return &s
+}
+
+// GetAwaitingReview returns my open, non-draft pull requests without an approval.
+func GetAwaitingReview(client *github.Client, ctx context.Context, orgs []string, user string) ([]*gh.PullRequest, error) {
+	query := gh.Query{
+		Name:  "awaiting-review",
+		Query: fmt.Sprintf("%s type:pr is:open draft:false -review:approved author:%s", gh.OrgQualifier(orgs), user),
+	}
+	return gh.SearchPullRequests(client, ctx, query)
+}
+
+// Render writes the standup in Slack mrkdwn: bold section titles, bullets
+// and <url|text> links.
+func (s *Standup) Render(w io.Writer) {
+	sections := []struct {
+		title string
+		items []string
+		empty string
+	}{
+		{"Yesterday", s.Yesterday, "Nothing tracked"},
+		{"Today", s.Today, "Nothing planned on the board"},
+		{"Blockers", s.Blockers, "None"},
+	}
+	for i, section := range sections {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "*%s*\n", section.title)
+		if len(section.items) == 0 {
+			fmt.Fprintf(w, "• %s\n", section.empty)
+			continue
+		}
+		for _, item := range section.items {
+			fmt.Fprintf(w, "• %s\n", item)
+		}
+	}
+}
+
+func pullRequestLink(pr *gh.PullRequest) string {
+	return fmt.Sprintf("<%s|%s#%d> %s", pr.HTMLURL, pr.Repo, pr.Number, escape(pr.Title))
+}
+
+func ticketSuffix(ticket string) string {
+	if ticket == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", ticket)
+}
+
+// escape replaces the characters Slack reserves for markup.
+func escape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+func sortedKeys(m map[string]*gh.ReviewsByPullRequest) []string {
+	keys := []string{}
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
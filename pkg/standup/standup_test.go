@@ -0,0 +1,61 @@
+package standup
+
+import (
+	"bytes"
+	"perf/pkg/gh"
+	"perf/pkg/jirautils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildAndRender(t *testing.T) {
+	activity := &Activity{
+		PullRequests: []*gh.PullRequest{
+			{Repo: "dev-envs", Number: 31, Title: "Add <compose> profiles", HTMLURL: "https://github.com/goflink/dev-envs/pull/31", Ticket: "DX-408", Created: true},
+			{Repo: "dev-envs", Number: 29, Title: "Fix CI", HTMLURL: "https://github.com/goflink/dev-envs/pull/29", MergedAt: time.Date(2025, 6, 16, 9, 0, 0, 0, time.UTC)},
+		},
+		Reviews: map[string]*gh.ReviewsByPullRequest{
+			"frank-i/infra/7": {PullRequest: &gh.PullRequest{Repo: "infra", Number: 7, Title: "Bump & pin", HTMLURL: "https://github.com/goflink/infra/pull/7"}},
+		},
+		Created: []*jirautils.Ticket{{Key: "DX-410", Title: "Document profiles"}},
+		Changelogs: []*jirautils.ChangelogItem{{
+			Ticket: &jirautils.Ticket{Key: "DX-400", Title: "Remove old scripts"},
+			Changes: []*jirautils.Change{
+				{Field: "status", From: "In Review", To: "Done"},
+				{Field: "labels", To: "cleanup"},
+			},
+		}},
+	}
+	board := &jirautils.Board{Columns: map[jirautils.Column][]*jirautils.Ticket{
+		jirautils.ColumnToDo:       {{Key: "DX-411", Title: "Profiles for CI", Status: "Selected for Development"}},
+		jirautils.ColumnInProgress: {{Key: "DX-408", Title: "Dev envs", Status: "In Progress"}},
+		jirautils.ColumnBlocked:    {{Key: "DX-300", Title: "Registry access", Status: "Blocked"}},
+	}}
+	awaiting := []*gh.PullRequest{activity.PullRequests[0]}
+
+	var out bytes.Buffer
+	Build(activity, board, awaiting).Render(&out)
+	assert.Equal(t, `*Yesterday*
+• Opened <https://github.com/goflink/dev-envs/pull/31|dev-envs#31> Add &lt;compose&gt; profiles (DX-408)
+• Merged <https://github.com/goflink/dev-envs/pull/29|dev-envs#29> Fix CI
+• Reviewed <https://github.com/goflink/infra/pull/7|infra#7> Bump &amp; pin
+• Created DX-410 Document profiles
+• Moved DX-400 Remove old scripts to Done
+
+*Today*
+• DX-408 Dev envs (In Progress)
+• DX-411 Profiles for CI (Selected for Development)
+
+*Blockers*
+• DX-300 Registry access is blocked
+• <https://github.com/goflink/dev-envs/pull/31|dev-envs#31> Add &lt;compose&gt; profiles is waiting for review
+`, out.String())
+}
+
+func TestRenderEmpty(t *testing.T) {
+	var out bytes.Buffer
+	Build(&Activity{}, nil, nil).Render(&out)
+	assert.Equal(t, "*Yesterday*\n• Nothing tracked\n\n*Today*\n• Nothing planned on the board\n\n*Blockers*\n• None\n", out.String())
+}